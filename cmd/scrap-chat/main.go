@@ -2,10 +2,12 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"github.com/xorvus/scrap-chat/pkg/platform"
+	"github.com/xorvus/scrap-chat/pkg/metrics"
 	"github.com/xorvus/scrap-chat/pkg/scrapchat"
 	"github.com/xorvus/scrap-chat/types"
 	"io"
@@ -26,8 +28,8 @@ func main() {
 	flag.BoolVar(&showVersion, "v", false, "Display program version (short form)")
 
 	var msgType string
-	flag.StringVar(&msgType, "type", "", "Type of scrap [live, video, info]")
-	flag.StringVar(&msgType, "t", "", "Type of scrap [live, video, info] (short form)")
+	flag.StringVar(&msgType, "type", "", "Type of scrap [live, replay, video, info]")
+	flag.StringVar(&msgType, "t", "", "Type of scrap [live, replay, video, info] (short form)")
 
 	var output string
 	flag.StringVar(&output, "output", "log", "Output result destination [log, file]")
@@ -41,14 +43,54 @@ func main() {
 	flag.StringVar(&customOutput, "custom-output", "", "Custom output template (e.g., \"TITLE: TITLE, ID: ID\")")
 	flag.StringVar(&customOutput, "co", "", "Custom output template (short form)")
 
+	var chatView string
+	flag.StringVar(&chatView, "chat-view", "live", "Chat view to capture [top, live]")
+	flag.StringVar(&chatView, "cv", "live", "Chat view to capture [top, live] (short form)")
+
+	var pacing string
+	flag.StringVar(&pacing, "pacing", "smooth", "Live chat delivery pacing [smooth, immediate, original]")
+	flag.StringVar(&pacing, "p", "smooth", "Live chat delivery pacing [smooth, immediate, original] (short form)")
+
+	var bufferSize int
+	flag.IntVar(&bufferSize, "buffer", 100, "Live chat buffer size in messages")
+	flag.IntVar(&bufferSize, "b", 100, "Live chat buffer size in messages (short form)")
+
+	var overflow string
+	flag.StringVar(&overflow, "overflow", "block", "What to do when the buffer is full [block, drop_oldest, drop_newest, spill]")
+	flag.StringVar(&overflow, "of", "block", "What to do when the buffer is full [block, drop_oldest, drop_newest, spill] (short form)")
+
+	var cookies string
+	flag.StringVar(&cookies, "cookies", "", "Netscape cookies file sent with every request")
+	flag.StringVar(&cookies, "c", "", "Netscape cookies file sent with every request (short form)")
+
+	var wait bool
+	flag.BoolVar(&wait, "wait", false, "Wait for upcoming streams and premieres to start")
+	flag.BoolVar(&wait, "w", false, "Wait for upcoming streams and premieres to start (short form)")
+
+	var proxy string
+	flag.StringVar(&proxy, "proxy", "", "Proxy URL [http, https, socks5], comma-separated for a rotating pool")
+	flag.StringVar(&proxy, "x", "", "Proxy URL [http, https, socks5], comma-separated for a rotating pool (short form)")
+
+	var metricsAddr string
+	flag.StringVar(&metricsAddr, "metrics", "", "Serve Prometheus metrics on this address, e.g. :9090")
+	flag.StringVar(&metricsAddr, "m", "", "Serve Prometheus metrics on this address, e.g. :9090 (short form)")
+
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options] <url>\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		fmt.Fprintf(os.Stderr, "  -v, --version           Display program version\n")
-		fmt.Fprintf(os.Stderr, "  -t, --type              Type of scrap [live, video, info]\n")
+		fmt.Fprintf(os.Stderr, "  -t, --type              Type of scrap [live, replay, video, info]\n")
 		fmt.Fprintf(os.Stderr, "  -o, --output            Output destination [log, file]\n")
 		fmt.Fprintf(os.Stderr, "  -f, --format            Format of result [default, json, custom]\n")
 		fmt.Fprintf(os.Stderr, "  -co, --custom-output     Custom output template (for format=custom)\n")
+		fmt.Fprintf(os.Stderr, "  -cv, --chat-view         Chat view to capture [top, live]\n")
+		fmt.Fprintf(os.Stderr, "  -p, --pacing            Live chat delivery pacing [smooth, immediate, original]\n")
+		fmt.Fprintf(os.Stderr, "  -b, --buffer            Live chat buffer size in messages\n")
+		fmt.Fprintf(os.Stderr, "  -of, --overflow          When the buffer is full [block, drop_oldest, drop_newest, spill]\n")
+		fmt.Fprintf(os.Stderr, "  -c, --cookies           Netscape cookies file sent with every request\n")
+		fmt.Fprintf(os.Stderr, "  -w, --wait              Wait for upcoming streams and premieres to start\n")
+		fmt.Fprintf(os.Stderr, "  -x, --proxy             Proxy URL [http, https, socks5], comma-separated for a rotating pool\n")
+		fmt.Fprintf(os.Stderr, "  -m, --metrics           Serve Prometheus metrics on this address, e.g. :9090\n")
 	}
 
 	flag.Parse()
@@ -65,7 +107,35 @@ func main() {
 	}
 	url := flag.Arg(0)
 
-	var chat platform.ChatFetcher = scrapchat.New("youtube")
+	opts := []scrapchat.Option{
+		scrapchat.WithChatView(types.ChatView(strings.ToLower(chatView))),
+		scrapchat.WithPacing(types.Pacing(strings.ToLower(pacing))),
+		scrapchat.WithBuffer(types.BufferOptions{
+			Size:     bufferSize,
+			Overflow: types.OverflowPolicy(strings.ToLower(overflow)),
+		}),
+		scrapchat.WithWaitForStart(wait),
+	}
+	if cookies != "" {
+		opts = append(opts, scrapchat.WithCookiesFile(cookies))
+	}
+	if proxy != "" {
+		opts = append(opts, scrapchat.WithProxyPool(0, strings.Split(proxy, ",")...))
+	}
+
+	if metricsAddr != "" {
+		go func() {
+			if err := metrics.Default.Serve(context.Background(), metricsAddr); err != nil {
+				log.Printf("Metrics listener: %v", err)
+			}
+		}()
+	}
+
+	chat, err := scrapchat.New("youtube", opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v. Use -h for help.\n", err)
+		os.Exit(1)
+	}
 
 	switch strings.ToLower(msgType) {
 	case "live":
@@ -74,7 +144,25 @@ func main() {
 			log.Fatalf("Error fetching live chat: %v", err)
 		}
 
-		handleLiveOutput(liveChat, output, format, customOutput)
+		handleLiveOutput(liveChat.Messages, "live_output.json", output, format, customOutput)
+		if stats := chat.Stats(); stats.DroppedOldest+stats.DroppedNewest > 0 {
+			log.Printf("Dropped %d messages, the output could not keep up", stats.DroppedOldest+stats.DroppedNewest)
+		}
+		if err := liveChat.Err(); errors.Is(err, types.ErrStreamEnded) {
+			log.Printf("Live chat closed: %v", err)
+		} else if err != nil {
+			log.Fatalf("Error fetching live chat: %v", err)
+		}
+	case "replay":
+		replayChat, err := chat.FetchChatReplay(url)
+		if err != nil {
+			log.Fatalf("Error fetching chat replay: %v", err)
+		}
+
+		handleLiveOutput(replayChat.Messages, "replay_output.json", output, format, customOutput)
+		if err := replayChat.Err(); err != nil {
+			log.Fatalf("Error fetching chat replay: %v", err)
+		}
 	case "video":
 		//chat.FetchVideoComments(url, nil)
 	case "info":
@@ -89,14 +177,14 @@ func main() {
 	}
 }
 
-func handleLiveOutput(chats <-chan *types.LiveChatMessage, output, format, customOutput string) {
+func handleLiveOutput(chats <-chan *types.LiveChatMessage, fileName, output, format, customOutput string) {
 	var writer *os.File
 	var err error
 	isFirst := true
 	needCloseArray := false
 
 	if output == "file" && format == "json" {
-		writer, err = os.OpenFile("live_output.json", os.O_CREATE|os.O_RDWR, 0644)
+		writer, err = os.OpenFile(fileName, os.O_CREATE|os.O_RDWR, 0644)
 		if err != nil {
 			log.Fatalf("Failed to open file: %v", err)
 		}
@@ -126,7 +214,7 @@ func handleLiveOutput(chats <-chan *types.LiveChatMessage, output, format, custo
 			}
 			isFirst = true
 		} else {
-			data, err := os.ReadFile("live_output.json")
+			data, err := os.ReadFile(fileName)
 			if err != nil {
 				log.Fatalf("Failed to read existing file: %v", err)
 			}
@@ -138,7 +226,7 @@ func handleLiveOutput(chats <-chan *types.LiveChatMessage, output, format, custo
 				}
 			}
 
-			err = os.WriteFile("live_output.json", trimmed, 0644)
+			err = os.WriteFile(fileName, trimmed, 0644)
 			if err != nil {
 				log.Fatalf("Failed to truncate file for append: %v", err)
 			}
@@ -252,6 +340,16 @@ func applyLiveCustomTemplate(template string, info *types.LiveChatMessage) strin
 		"AUTHOR_URL", info.Author.URL,
 		"AUTHOR_THUMBNAIL", info.Author.Thumbnail,
 		"TIME", strconv.FormatInt(info.Timestamp, 10),
+		"TYPE", string(info.Type),
+		"AMOUNT", paidAmount(info),
+		"OFFSET", strconv.FormatInt(info.VideoOffsetMs, 10),
 	)
 	return replacer.Replace(template)
 }
+
+func paidAmount(info *types.LiveChatMessage) string {
+	if info.Paid == nil {
+		return ""
+	}
+	return info.Paid.AmountText
+}
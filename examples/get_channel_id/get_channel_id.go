@@ -2,12 +2,15 @@ package main
 
 import (
 	"fmt"
-	"github.com/xorvus/scrap-chat/pkg/platform"
 	"github.com/xorvus/scrap-chat/pkg/scrapchat"
 )
 
 func main() {
-	var chat platform.ChatFetcher = scrapchat.New("youtube")
+	chat, err := scrapchat.New("youtube")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 
 	channelInfo, err := chat.FetchChannelInfo("https://www.youtube.com/@LofiGirl")
 	if err != nil {
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"github.com/xorvus/scrap-chat/pkg/platform"
 	"github.com/xorvus/scrap-chat/pkg/scrapchat"
 	"log"
 	_ "net/http/pprof"
@@ -10,7 +9,11 @@ import (
 )
 
 func main() {
-	var chat platform.ChatFetcher = scrapchat.New("youtube", true)
+	chat, err := scrapchat.New("youtube", scrapchat.WithVerbose(true))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: program <arg>")
 		return
@@ -24,7 +27,7 @@ func main() {
 		return
 	}
 
-	for msg := range data {
+	for msg := range data.Messages {
 		log.Printf("(%s) %s\n", msg.Author.Name, msg.Message)
 	}
 }
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"github.com/xorvus/scrap-chat/pkg/scrapchat"
+	"github.com/xorvus/scrap-chat/pkg/ytfake"
+	"log"
+)
+
+// Captures a scripted live chat from a local fake YouTube, no network needed.
+func main() {
+	srv := ytfake.New(ytfake.Options{
+		Continuation: ytfake.Invalidation,
+		Batches: [][]ytfake.Message{
+			{{ID: "1", Author: "alice", Text: "hello"}, {ID: "2", Author: "bob", Text: "hi alice"}},
+			{{ID: "3", Author: "alice", Text: "bye"}},
+		},
+	})
+	defer srv.Close()
+
+	chat, err := scrapchat.New("youtube", srv.Options()...)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	data, err := chat.FetchLiveChat(srv.WatchURL())
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	for msg := range data.Messages {
+		log.Printf("(%s) %s\n", msg.Author.Name, msg.Message)
+	}
+	log.Println("Capture ended:", data.Err())
+}
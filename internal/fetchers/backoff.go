@@ -0,0 +1,107 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"github.com/xorvus/scrap-chat/types"
+	"log/slog"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// backoff tracks consecutive failures of one capture.
+type backoff struct {
+	policy  types.ReconnectPolicy
+	logger  *slog.Logger
+	attempt int
+	last    types.ReconnectEvent
+}
+
+func newBackoff(policy *types.ReconnectPolicy, logger *slog.Logger) *backoff {
+	p := types.DefaultReconnectPolicy
+	if policy != nil {
+		p = *policy
+		if p.InitialDelay <= 0 {
+			p.InitialDelay = types.DefaultReconnectPolicy.InitialDelay
+		}
+		if p.MaxDelay <= 0 {
+			p.MaxDelay = types.DefaultReconnectPolicy.MaxDelay
+		}
+		if p.Multiplier < 1 {
+			p.Multiplier = types.DefaultReconnectPolicy.Multiplier
+		}
+		p.Jitter = math.Min(math.Max(p.Jitter, 0), 1)
+	}
+	return &backoff{policy: p, logger: logger}
+}
+
+// wait sleeps before retrying after err. It returns false when err cannot be
+// retried, the attempts are exhausted or ctx is done.
+func (b *backoff) wait(ctx context.Context, err error) bool {
+	if !retryable(err) {
+		return false
+	}
+	b.attempt++
+	if b.policy.MaxAttempts > 0 && b.attempt > b.policy.MaxAttempts {
+		return false
+	}
+
+	b.last = types.ReconnectEvent{Attempt: b.attempt, Delay: b.delay(err), Err: err}
+	b.logger.Warn("reconnecting", "attempt", b.attempt, "delay", b.last.Delay, "err", err)
+	if b.policy.OnReconnect != nil {
+		b.policy.OnReconnect(b.last)
+	}
+
+	return sleepCtx(ctx, b.last.Delay)
+}
+
+func (b *backoff) delay(err error) time.Duration {
+	d := float64(b.policy.InitialDelay) * math.Pow(b.policy.Multiplier, float64(b.attempt-1))
+	d = math.Min(d, float64(b.policy.MaxDelay))
+	d -= d * b.policy.Jitter * rand.Float64()
+
+	// Retry-After is a lower bound, it is not shortened by the jitter.
+	var fe *types.FetchError
+	if errors.As(err, &fe) && fe.RetryAfter > time.Duration(d) {
+		return fe.RetryAfter
+	}
+	return time.Duration(d)
+}
+
+// reset marks a successful request, OnReconnected fires when it ends a run of
+// failures.
+func (b *backoff) reset() {
+	if b.attempt == 0 {
+		return
+	}
+	b.logger.Info("reconnected", "attempts", b.attempt)
+	if b.policy.OnReconnected != nil {
+		b.policy.OnReconnected(b.last)
+	}
+	b.attempt = 0
+}
+
+// retry runs op until it succeeds or b gives up, the last error is returned.
+func (b *backoff) retry(ctx context.Context, op func() error) error {
+	for {
+		err := op()
+		if err == nil {
+			b.reset()
+			return nil
+		}
+		if !b.wait(ctx, err) {
+			return err
+		}
+	}
+}
+
+// retryable reports whether reconnecting may help: network failures and
+// rejected sessions, which are rebuilt before the next attempt.
+func retryable(err error) bool {
+	var fe *types.FetchError
+	if !errors.As(err, &fe) {
+		return false
+	}
+	return fe.Kind == types.ErrorNetwork || fe.Kind == types.ErrorSessionRejected
+}
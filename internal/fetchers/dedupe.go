@@ -0,0 +1,97 @@
+package fetchers
+
+import (
+	"github.com/xorvus/scrap-chat/types"
+	"sort"
+	"time"
+)
+
+const (
+	dedupeWindow = 5 * time.Minute
+	dedupeSize   = 10000
+)
+
+// dedupe drops messages that were already delivered. Keys are kept for
+// window and at most size of them, oldest first out.
+type dedupe struct {
+	window time.Duration
+	size   int
+	seen   map[string]time.Time
+	order  []string
+	// last is the newest server timestamp passed on so far.
+	last time.Time
+}
+
+func newDedupe(window time.Duration, size int) *dedupe {
+	return &dedupe{
+		window: window,
+		size:   size,
+		seen:   make(map[string]time.Time, size),
+	}
+}
+
+// filter returns the messages of batch not seen before, sorted by timestamp.
+// Moderation, banner and poll events reuse the ID of the message or poll
+// they refer to, so the key is the type and the ID. Poll updates share both
+// and are always kept.
+//
+// Order holds within a batch and across batches, except for a message first
+// seen after a newer one was passed on, e.g. one held for review. It is
+// still kept, sorted first in its batch, and counted in late.
+func (d *dedupe) filter(batch []types.YTChatMessage) (fresh []types.YTChatMessage, late int) {
+	now := time.Now()
+	d.expire(now)
+
+	fresh = batch[:0:0]
+	for _, msg := range batch {
+		if msg.ID == "" || msg.Type == types.LiveChatPollUpdated {
+			fresh = append(fresh, msg)
+			continue
+		}
+		key := string(msg.Type) + ":" + msg.ID
+		if _, ok := d.seen[key]; ok {
+			continue
+		}
+		d.seen[key] = now
+		d.order = append(d.order, key)
+		fresh = append(fresh, msg)
+	}
+
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return fresh[i].Timestamp.Before(fresh[j].Timestamp)
+	})
+
+	// Events stamped on arrival are not compared, they would hide every
+	// message of the next batch.
+	for _, msg := range fresh {
+		if !serverTimed(msg) {
+			continue
+		}
+		if msg.Timestamp.Before(d.last) {
+			late++
+			continue
+		}
+		d.last = msg.Timestamp
+	}
+	return fresh, late
+}
+
+// serverTimed reports whether msg carries the timestamp YouTube gave it.
+// Moderation, banner and poll events are stamped when they are parsed.
+func serverTimed(msg types.YTChatMessage) bool {
+	return !msg.Timestamp.IsZero() && msg.Moderation == nil && msg.Banner == nil && msg.Poll == nil
+}
+
+func (d *dedupe) expire(now time.Time) {
+	n := 0
+	for n < len(d.order) {
+		if len(d.order)-n <= d.size && now.Sub(d.seen[d.order[n]]) < d.window {
+			break
+		}
+		delete(d.seen, d.order[n])
+		n++
+	}
+	if n > 0 {
+		d.order = append(d.order[:0], d.order[n:]...)
+	}
+}
@@ -0,0 +1,71 @@
+package fetchers
+
+import (
+	"github.com/xorvus/scrap-chat/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func chatAt(id string, sec int) types.YTChatMessage {
+	return types.YTChatMessage{ID: id, Type: types.LiveChatText, Timestamp: time.Unix(int64(sec), 0)}
+}
+
+func ids(msgs []types.YTChatMessage) []string {
+	out := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		out = append(out, msg.ID)
+	}
+	return out
+}
+
+func TestDedupeFilter(t *testing.T) {
+	d := newDedupe(time.Minute, 100)
+
+	fresh, late := d.filter([]types.YTChatMessage{chatAt("b", 2), chatAt("a", 1), chatAt("a", 1)})
+	if got, want := ids(fresh), []string{"a", "b"}; !reflect.DeepEqual(got, want) || late != 0 {
+		t.Fatalf("first batch = %v late %d, want %v late 0", got, late, want)
+	}
+
+	// b is a duplicate, c is older than b and still delivered.
+	fresh, late = d.filter([]types.YTChatMessage{chatAt("b", 2), chatAt("d", 4), chatAt("c", 1)})
+	if got, want := ids(fresh), []string{"c", "d"}; !reflect.DeepEqual(got, want) || late != 1 {
+		t.Fatalf("second batch = %v late %d, want %v late 1", got, late, want)
+	}
+
+	// A deletion reuses the ID of the message it refers to.
+	deleted := chatAt("d", 0)
+	deleted.Type = types.LiveChatDeleted
+	deleted.Timestamp = time.Unix(100, 0)
+	deleted.Moderation = &types.ModerationDetails{TargetID: "d"}
+	fresh, late = d.filter([]types.YTChatMessage{deleted})
+	if len(fresh) != 1 || late != 0 {
+		t.Fatalf("deletion = %v late %d, want it kept", ids(fresh), late)
+	}
+	if !d.last.Equal(time.Unix(4, 0)) {
+		t.Errorf("last = %v, an event stamped on arrival must not move it", d.last)
+	}
+}
+
+func TestDedupeKeepsPollUpdates(t *testing.T) {
+	d := newDedupe(time.Minute, 100)
+	update := types.YTChatMessage{ID: "poll", Type: types.LiveChatPollUpdated, Poll: &types.PollDetails{}}
+	anonymous := types.YTChatMessage{Type: types.LiveChatText}
+
+	for i := 0; i < 2; i++ {
+		fresh, _ := d.filter([]types.YTChatMessage{update, anonymous})
+		if len(fresh) != 2 {
+			t.Fatalf("batch %d kept %d messages, want 2", i, len(fresh))
+		}
+	}
+}
+
+func TestDedupeExpire(t *testing.T) {
+	d := newDedupe(time.Minute, 2)
+	d.filter([]types.YTChatMessage{chatAt("a", 1), chatAt("b", 2), chatAt("c", 3)})
+
+	fresh, _ := d.filter([]types.YTChatMessage{chatAt("a", 4), chatAt("c", 5)})
+	if got, want := ids(fresh), []string{"a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after size limit = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package fetchers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xorvus/scrap-chat/types"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func fetchError(op string, kind types.ErrorKind, err error) error {
+	return &types.FetchError{Kind: kind, Op: op, Err: err}
+}
+
+// parseError is a protocol error for a response that could not be parsed.
+func (y *Youtube) parseError(op string, err error) error {
+	y.metrics.parseFailure(op)
+	return fetchError(op, types.ErrorProtocol, err)
+}
+
+// checkStatus classifies non-2xx responses, Retry-After is kept for 429/503.
+func checkStatus(op string, resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	kind := types.ErrorProtocol
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		kind = types.ErrorNetwork
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		kind = types.ErrorSessionRejected
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		kind = types.ErrorStreamEnded
+	}
+
+	return &types.FetchError{
+		Kind:       kind,
+		Op:         op,
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+		Err:        fmt.Errorf("unexpected status %s", resp.Status),
+	}
+}
+
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func isTransient(err error) bool {
+	var fe *types.FetchError
+	return errors.As(err, &fe) && fe.Temporary()
+}
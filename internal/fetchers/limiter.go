@@ -0,0 +1,191 @@
+package fetchers
+
+import (
+	"context"
+	"github.com/xorvus/scrap-chat/types"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	limitersMu sync.Mutex
+	limiters   = map[types.RateLimits]*rateLimiter{}
+)
+
+// tokenBucket hands out one token per request. A request that finds the
+// bucket empty reserves the next token and waits for it, so waiting requests
+// are served in order.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit types.RateLimit) *tokenBucket {
+	if limit.PerSecond <= 0 {
+		return nil
+	}
+	burst := math.Max(float64(limit.Burst), 1)
+	return &tokenBucket{rate: limit.PerSecond, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// reserve takes a token and returns how long to wait before using it.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back a token reserved by a request that gave up waiting.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+	b.mu.Unlock()
+}
+
+// rateLimiter holds one bucket per endpoint class, a nil bucket is unlimited.
+type rateLimiter struct {
+	buckets map[types.EndpointClass]*tokenBucket
+}
+
+// sharedLimiter returns the process-wide limiter for limits.
+func sharedLimiter(limits types.RateLimits) *rateLimiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	if l, ok := limiters[limits]; ok {
+		return l
+	}
+	l := &rateLimiter{buckets: map[types.EndpointClass]*tokenBucket{
+		types.EndpointPage:      newTokenBucket(limits.Page),
+		types.EndpointInnerTube: newTokenBucket(limits.InnerTube),
+		types.EndpointSignaler:  newTokenBucket(limits.Signaler),
+	}}
+	limiters[limits] = l
+	return l
+}
+
+// wait blocks until a request of class may go, it returns the time spent
+// waiting.
+func (l *rateLimiter) wait(ctx context.Context, class types.EndpointClass) (time.Duration, error) {
+	b := l.buckets[class]
+	if b == nil {
+		return 0, nil
+	}
+	d := b.reserve()
+	if d == 0 {
+		return 0, nil
+	}
+	if !sleepCtx(ctx, d) {
+		b.cancel()
+		return d, ctx.Err()
+	}
+	return d, nil
+}
+
+// endpointClass tells the budget of a request from its path.
+func endpointClass(req *http.Request) types.EndpointClass {
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/youtubei/"):
+		return types.EndpointInnerTube
+	case strings.HasPrefix(req.URL.Path, "/punctual/"):
+		return types.EndpointSignaler
+	}
+	return types.EndpointPage
+}
+
+type classStats struct {
+	requests atomic.Uint64
+	waited   atomic.Uint64
+	waitTime atomic.Int64
+}
+
+// rateStats are the rate limiter counters of one fetcher.
+type rateStats struct {
+	page      classStats
+	innerTube classStats
+	signaler  classStats
+}
+
+func (s *rateStats) class(class types.EndpointClass) *classStats {
+	switch class {
+	case types.EndpointInnerTube:
+		return &s.innerTube
+	case types.EndpointSignaler:
+		return &s.signaler
+	}
+	return &s.page
+}
+
+func (s *rateStats) snapshot() map[types.EndpointClass]types.RateLimitStats {
+	stats := make(map[types.EndpointClass]types.RateLimitStats, 3)
+	for _, class := range []types.EndpointClass{types.EndpointPage, types.EndpointInnerTube, types.EndpointSignaler} {
+		c := s.class(class)
+		stats[class] = types.RateLimitStats{
+			Requests: c.requests.Load(),
+			Waited:   c.waited.Load(),
+			WaitTime: time.Duration(c.waitTime.Load()),
+		}
+	}
+	return stats
+}
+
+// limitedTransport holds every request until its endpoint class has budget,
+// then adds the fetcher headers and cookies.
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+	stats   rateStats
+	logger  *slog.Logger
+	metrics *fetcherMetrics
+	headers func(http.Header)
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	class := endpointClass(req)
+	d, err := t.limiter.wait(req.Context(), class)
+
+	c := t.stats.class(class)
+	c.requests.Add(1)
+	if d > 0 {
+		c.waited.Add(1)
+		c.waitTime.Add(int64(d))
+		t.logger.Debug("rate limited", "endpoint", req.URL.Path, "class", class, "wait", d)
+	}
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	if t.headers != nil {
+		req = req.Clone(req.Context())
+		t.headers(req.Header)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.logger.Debug("request failed", "endpoint", req.URL.Path, "class", class, "latency", time.Since(start), "err", err)
+		return nil, err
+	}
+	t.metrics.response(class, resp.StatusCode)
+	t.logger.Debug("request", "endpoint", req.URL.Path, "class", class, "status", resp.StatusCode, "latency", time.Since(start))
+	return resp, nil
+}
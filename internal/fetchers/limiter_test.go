@@ -0,0 +1,39 @@
+package fetchers
+
+import (
+	"github.com/xorvus/scrap-chat/types"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	if b := newTokenBucket(types.RateLimit{}); b != nil {
+		t.Errorf("bucket without a rate = %+v, want unlimited", b)
+	}
+
+	b := newTokenBucket(types.RateLimit{PerSecond: 10, Burst: 2})
+	for i := 0; i < 2; i++ {
+		if d := b.reserve(); d != 0 {
+			t.Fatalf("burst token %d waits %v", i, d)
+		}
+	}
+
+	// Waiting requests queue up, 100ms per token.
+	first, second := b.reserve(), b.reserve()
+	if first <= 50*time.Millisecond || first > 100*time.Millisecond {
+		t.Errorf("first wait = %v, want about 100ms", first)
+	}
+	if second <= first || second > 200*time.Millisecond {
+		t.Errorf("second wait = %v, want about 200ms", second)
+	}
+
+	b.cancel()
+	b.cancel()
+	b.last = b.last.Add(-time.Second)
+	if d := b.reserve(); d != 0 {
+		t.Errorf("refilled bucket waits %v", d)
+	}
+	if b.tokens > b.burst {
+		t.Errorf("tokens = %v, over the burst of %v", b.tokens, b.burst)
+	}
+}
@@ -0,0 +1,51 @@
+package fetchers
+
+import (
+	"github.com/xorvus/scrap-chat/pkg/metrics"
+	"github.com/xorvus/scrap-chat/types"
+	"strconv"
+	"time"
+)
+
+// fetcherMetrics are the metric handles of a fetcher, fetchers sharing a
+// registry add up into the same series.
+type fetcherMetrics struct {
+	messages      metrics.CounterVec
+	polls         metrics.CounterVec
+	reconnects    metrics.Counter
+	signaler      metrics.CounterVec
+	responses     metrics.CounterVec
+	parseFailures metrics.CounterVec
+	latency       metrics.Histogram
+	activeStreams metrics.Gauge
+}
+
+func newFetcherMetrics(reg *metrics.Registry) *fetcherMetrics {
+	return &fetcherMetrics{
+		messages:      reg.CounterVec("scrapchat_messages_total", "Live chat messages delivered, by type.", "type"),
+		polls:         reg.CounterVec("scrapchat_polls_total", "get_live_chat requests, by continuation kind.", "continuation"),
+		reconnects:    reg.Counter("scrapchat_longpoll_reconnects_total", "Signaler long-poll reconnects."),
+		signaler:      reg.CounterVec("scrapchat_signaler_calls_total", "Signaler session calls, by call.", "call"),
+		responses:     reg.CounterVec("scrapchat_http_responses_total", "HTTP responses, by endpoint class and status code.", "endpoint", "code"),
+		parseFailures: reg.CounterVec("scrapchat_parse_failures_total", "Responses that could not be parsed, by operation.", "op"),
+		latency:       reg.Histogram("scrapchat_delivery_latency_seconds", "Time from the message timestamp to its delivery.", nil),
+		activeStreams: reg.Gauge("scrapchat_active_streams", "Live chat captures running."),
+	}
+}
+
+func (m *fetcherMetrics) response(class types.EndpointClass, code int) {
+	m.responses.With(string(class), strconv.Itoa(code)).Inc()
+}
+
+func (m *fetcherMetrics) parseFailure(op string) {
+	m.parseFailures.With(op).Inc()
+}
+
+// delivered records a live message queued for output. Events stamped on
+// arrival have no latency to observe.
+func (m *fetcherMetrics) delivered(msg types.YTChatMessage) {
+	m.messages.With(string(msg.Type)).Inc()
+	if serverTimed(msg) {
+		m.latency.Observe(time.Since(msg.Timestamp).Seconds())
+	}
+}
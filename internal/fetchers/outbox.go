@@ -0,0 +1,298 @@
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/xorvus/scrap-chat/types"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultBufferSize = 100
+
+// outputStats count the output of one capture, every count is added to
+// parent too so the fetcher keeps the totals of all its captures.
+type outputStats struct {
+	parent        *outputStats
+	delivered     atomic.Uint64
+	droppedOldest atomic.Uint64
+	droppedNewest atomic.Uint64
+	spilled       atomic.Uint64
+	pending       atomic.Int64
+	late          atomic.Uint64
+}
+
+func (s *outputStats) addDelivered() {
+	for ; s != nil; s = s.parent {
+		s.delivered.Add(1)
+	}
+}
+
+func (s *outputStats) addDroppedOldest() {
+	for ; s != nil; s = s.parent {
+		s.droppedOldest.Add(1)
+	}
+}
+
+func (s *outputStats) addDroppedNewest() {
+	for ; s != nil; s = s.parent {
+		s.droppedNewest.Add(1)
+	}
+}
+
+func (s *outputStats) addSpilled() {
+	for ; s != nil; s = s.parent {
+		s.spilled.Add(1)
+		s.pending.Add(1)
+	}
+}
+
+func (s *outputStats) addPending(n int64) {
+	for ; s != nil; s = s.parent {
+		s.pending.Add(n)
+	}
+}
+
+func (s *outputStats) addLate(n uint64) {
+	for ; s != nil; s = s.parent {
+		s.late.Add(n)
+	}
+}
+
+func (s *outputStats) snapshot() types.Stats {
+	return types.Stats{
+		Delivered:     s.delivered.Load(),
+		DroppedOldest: s.droppedOldest.Load(),
+		DroppedNewest: s.droppedNewest.Load(),
+		Spilled:       s.spilled.Load(),
+		SpillPending:  s.pending.Load(),
+		Late:          s.late.Load(),
+	}
+}
+
+// outbox is the buffered output of one live capture. What happens when the
+// buffer is full depends on the overflow policy.
+type outbox struct {
+	out    chan *types.LiveChatMessage
+	policy types.OverflowPolicy
+	dir    string
+	stats  *outputStats
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	file    *os.File
+	decoder *json.Decoder
+	pending int
+	closing bool
+	wake    chan struct{}
+	pumping sync.WaitGroup
+}
+
+func newOutbox(opts types.BufferOptions, stats *outputStats, logger *slog.Logger) *outbox {
+	if opts.Size <= 0 {
+		opts.Size = defaultBufferSize
+	}
+	if opts.Overflow == "" {
+		opts.Overflow = types.OverflowBlock
+	}
+	return &outbox{
+		out:    make(chan *types.LiveChatMessage, opts.Size),
+		policy: opts.Overflow,
+		dir:    opts.SpillDir,
+		stats:  stats,
+		logger: logger,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// send queues m. queued is false when the policy dropped it or ctx was done
+// first, a spilled message is queued. ok is false when ctx is done.
+func (o *outbox) send(ctx context.Context, m *types.LiveChatMessage) (queued, ok bool) {
+	switch o.policy {
+	case types.OverflowDropNewest:
+		select {
+		case o.out <- m:
+			o.stats.addDelivered()
+			queued = true
+		default:
+			o.stats.addDroppedNewest()
+		}
+		return queued, ctx.Err() == nil
+
+	case types.OverflowDropOldest:
+		for {
+			select {
+			case o.out <- m:
+				o.stats.addDelivered()
+				return true, ctx.Err() == nil
+			default:
+			}
+			select {
+			case <-o.out:
+				o.stats.addDroppedOldest()
+			default:
+			}
+		}
+
+	case types.OverflowSpill:
+		if o.spilling() || !o.trySend(m) {
+			if err := o.spill(ctx, m); err != nil {
+				// Without a disk queue the capture falls back to blocking.
+				o.logger.Error("spill failed, blocking instead", "err", err)
+				sent := o.blockingSend(ctx, m)
+				return sent, sent
+			}
+		}
+		return true, ctx.Err() == nil
+	}
+
+	sent := o.blockingSend(ctx, m)
+	return sent, sent
+}
+
+func (o *outbox) trySend(m *types.LiveChatMessage) bool {
+	select {
+	case o.out <- m:
+		o.stats.addDelivered()
+		return true
+	default:
+		return false
+	}
+}
+
+func (o *outbox) blockingSend(ctx context.Context, m *types.LiveChatMessage) bool {
+	select {
+	case o.out <- m:
+		o.stats.addDelivered()
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (o *outbox) spilling() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.pending > 0
+}
+
+// spill appends m to the disk queue, the pump moves it back to the channel
+// once the consumer catches up. Messages keep their order.
+func (o *outbox) spill(ctx context.Context, m *types.LiveChatMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil {
+		file, err := os.CreateTemp(o.dir, "scrap-chat-*.jsonl")
+		if err != nil {
+			return err
+		}
+		o.file = file
+		o.decoder = json.NewDecoder(io.NewSectionReader(file, 0, 1<<62))
+		o.pumping.Add(1)
+		go o.pump(ctx, o.wake)
+	}
+
+	if err := json.NewEncoder(o.file).Encode(m); err != nil {
+		return err
+	}
+	o.pending++
+	o.stats.addSpilled()
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (o *outbox) pump(ctx context.Context, wake <-chan struct{}) {
+	defer o.pumping.Done()
+	for {
+		m, ok := o.next()
+		if !ok {
+			select {
+			case <-wake:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+		if m == nil {
+			// The outbox is closing and the queue is empty.
+			return
+		}
+		if !o.blockingSend(ctx, m) {
+			return
+		}
+		o.sent()
+	}
+}
+
+// next reads the oldest spilled message. ok is false when the queue is empty,
+// a nil message with ok means the outbox is closing. The message stays
+// pending until sent, so new messages cannot overtake it.
+func (o *outbox) next() (*types.LiveChatMessage, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.pending == 0 {
+		return nil, o.closing
+	}
+
+	var m types.LiveChatMessage
+	if err := o.decoder.Decode(&m); err != nil {
+		o.logger.Error("spill queue failed", "err", err)
+		o.stats.addPending(-int64(o.pending))
+		o.pending = 0
+		o.reset()
+		return nil, o.closing
+	}
+	return &m, true
+}
+
+func (o *outbox) sent() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.pending--
+	o.stats.addPending(-1)
+	if o.pending == 0 {
+		o.reset()
+	}
+}
+
+// reset empties the drained disk queue so it does not grow for the whole
+// capture.
+func (o *outbox) reset() {
+	if err := o.file.Truncate(0); err != nil {
+		o.logger.Error("spill queue failed", "err", err)
+		return
+	}
+	if _, err := o.file.Seek(0, io.SeekStart); err != nil {
+		o.logger.Error("spill queue failed", "err", err)
+		return
+	}
+	o.decoder = json.NewDecoder(io.NewSectionReader(o.file, 0, 1<<62))
+}
+
+// close waits until the disk queue is drained, unless ctx is done, then
+// closes the channel and removes the queue file.
+func (o *outbox) close() {
+	o.mu.Lock()
+	o.closing = true
+	o.mu.Unlock()
+	close(o.wake)
+
+	o.pumping.Wait()
+	close(o.out)
+
+	if o.file != nil {
+		o.file.Close()
+		os.Remove(o.file.Name())
+		o.stats.addPending(-int64(o.pending))
+	}
+}
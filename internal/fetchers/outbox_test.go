@@ -0,0 +1,104 @@
+package fetchers
+
+import (
+	"context"
+	"github.com/xorvus/scrap-chat/types"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func sendAll(t *testing.T, box *outbox, ids ...string) []bool {
+	t.Helper()
+	queued := make([]bool, 0, len(ids))
+	for _, id := range ids {
+		q, ok := box.send(context.Background(), &types.LiveChatMessage{ID: id})
+		if !ok {
+			t.Fatalf("send %s: not ok", id)
+		}
+		queued = append(queued, q)
+	}
+	return queued
+}
+
+func drain(box *outbox) []string {
+	var got []string
+	for m := range box.out {
+		got = append(got, m.ID)
+	}
+	return got
+}
+
+func TestOutboxDropNewest(t *testing.T) {
+	var stats outputStats
+	box := newOutbox(types.BufferOptions{Size: 2, Overflow: types.OverflowDropNewest}, &stats, discard)
+
+	queued := sendAll(t, box, "a", "b", "c")
+	if want := []bool{true, true, false}; !reflect.DeepEqual(queued, want) {
+		t.Errorf("queued = %v, want %v", queued, want)
+	}
+	box.close()
+	if got, want := drain(box), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+	if s := stats.snapshot(); s.Delivered != 2 || s.DroppedNewest != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestOutboxDropOldest(t *testing.T) {
+	var stats outputStats
+	box := newOutbox(types.BufferOptions{Size: 2, Overflow: types.OverflowDropOldest}, &stats, discard)
+
+	sendAll(t, box, "a", "b", "c", "d")
+	box.close()
+	if got, want := drain(box), []string{"c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+	if s := stats.snapshot(); s.Delivered != 4 || s.DroppedOldest != 2 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestOutboxBlock(t *testing.T) {
+	var stats outputStats
+	box := newOutbox(types.BufferOptions{Size: 1}, &stats, discard)
+	sendAll(t, box, "a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if queued, ok := box.send(ctx, &types.LiveChatMessage{ID: "b"}); queued || ok {
+		t.Errorf("send on a full buffer = %v, %v, want it to wait for ctx", queued, ok)
+	}
+	if s := stats.snapshot(); s.Delivered != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestOutboxSpill(t *testing.T) {
+	parent := &outputStats{}
+	stats := &outputStats{parent: parent}
+	box := newOutbox(types.BufferOptions{Size: 2, Overflow: types.OverflowSpill, SpillDir: t.TempDir()}, stats, discard)
+
+	queued := sendAll(t, box, "a", "b", "c", "d", "e")
+	if want := []bool{true, true, true, true, true}; !reflect.DeepEqual(queued, want) {
+		t.Errorf("queued = %v, want %v", queued, want)
+	}
+
+	done := make(chan []string)
+	go func() { done <- drain(box) }()
+	box.close()
+	if got, want := <-done, []string{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+
+	for name, s := range map[string]types.Stats{"capture": stats.snapshot(), "fetcher": parent.snapshot()} {
+		if s.Delivered != 5 || s.Spilled != 3 || s.SpillPending != 0 {
+			t.Errorf("%s stats = %+v", name, s)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const defaultProxyBench = 5 * time.Minute
+
+// ProxyPool hands out proxies round robin. Every session keeps its proxy
+// until the proxy is benched after a 429 answer. A pool can be shared by
+// several fetchers.
+type ProxyPool struct {
+	mu      sync.Mutex
+	proxies []*url.URL
+	benched []time.Time
+	next    int
+	bench   time.Duration
+}
+
+type sessionProxyKey struct{}
+
+// sessionProxy is the proxy of one session, carried by its request context.
+type sessionProxy struct {
+	mu  sync.Mutex
+	url *url.URL
+}
+
+// NewProxyPool parses http, https and socks5 proxy URLs. A proxy that gets a
+// 429 answer is benched for the Retry-After delay, or bench when there is
+// none. bench defaults to 5 minutes.
+func NewProxyPool(proxies []string, bench time.Duration) (*ProxyPool, error) {
+	if bench <= 0 {
+		bench = defaultProxyBench
+	}
+	p := &ProxyPool{bench: bench}
+	for _, raw := range proxies {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %q: %w", raw, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("invalid proxy %q: unsupported scheme", raw)
+		}
+		p.proxies = append(p.proxies, u)
+	}
+	if len(p.proxies) == 0 {
+		return nil, errors.New("empty proxy pool")
+	}
+	p.benched = make([]time.Time, len(p.proxies))
+	return p, nil
+}
+
+// pick returns the next proxy that is not benched. When all of them are, the
+// one back first is used.
+func (p *ProxyPool) pick() *url.URL {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	best := p.next
+	for i := range p.proxies {
+		n := (p.next + i) % len(p.proxies)
+		if !p.benched[n].After(now) {
+			best = n
+			break
+		}
+		if p.benched[n].Before(p.benched[best]) {
+			best = n
+		}
+	}
+	p.next = (best + 1) % len(p.proxies)
+	return p.proxies[best]
+}
+
+func (p *ProxyPool) isBenched(u *url.URL) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, proxy := range p.proxies {
+		if proxy == u {
+			return p.benched[i].After(time.Now())
+		}
+	}
+	return false
+}
+
+// benchProxy keeps u out of rotation for d, or the pool default when d is 0.
+func (p *ProxyPool) benchProxy(u *url.URL, d time.Duration) {
+	if d <= 0 {
+		d = p.bench
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, proxy := range p.proxies {
+		if proxy == u {
+			p.benched[i] = time.Now().Add(d)
+		}
+	}
+}
+
+// withSessionProxy returns ctx carrying a proxy slot for one session.
+func (p *ProxyPool) withSessionProxy(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionProxyKey{}, &sessionProxy{url: p.pick()})
+}
+
+// proxy is the http.Transport Proxy func. A session switches to the next
+// proxy once its own is benched.
+func (p *ProxyPool) proxy(req *http.Request) (*url.URL, error) {
+	sp, ok := req.Context().Value(sessionProxyKey{}).(*sessionProxy)
+	if !ok {
+		return p.pick(), nil
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	if p.isBenched(sp.url) {
+		sp.url = p.pick()
+	}
+	return sp.url, nil
+}
+
+// benchingTransport benches the proxy of a request that got a 429 answer.
+type benchingTransport struct {
+	base *http.Transport
+	pool *ProxyPool
+}
+
+func (t *benchingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	sp, ok := req.Context().Value(sessionProxyKey{}).(*sessionProxy)
+	if !ok {
+		// Requests outside a session get a proxy of their own.
+		sp = &sessionProxy{url: t.pool.pick()}
+		req = req.WithContext(context.WithValue(req.Context(), sessionProxyKey{}, sp))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		sp.mu.Lock()
+		u := sp.url
+		sp.mu.Unlock()
+		t.pool.benchProxy(u, retryAfter(resp.Header.Get("Retry-After")))
+	}
+	return resp, err
+}
+
+// newTransport builds the one RoundTripper every request goes through. base
+// may be nil, a proxy pool needs it to be an *http.Transport.
+func newTransport(base http.RoundTripper, pool *ProxyPool) (http.RoundTripper, error) {
+	if base == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.MaxResponseHeaderBytes = 1 << 20
+		base = t
+	}
+	if pool == nil {
+		return base, nil
+	}
+
+	t, ok := base.(*http.Transport)
+	if !ok {
+		return nil, errors.New("a proxy pool needs an *http.Transport")
+	}
+	t = t.Clone()
+	t.Proxy = pool.proxy
+	return &benchingTransport{base: t, pool: pool}, nil
+}
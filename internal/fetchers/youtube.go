@@ -10,9 +10,10 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"github.com/tidwall/gjson"
 	"github.com/xorvus/scrap-chat/internal/utils"
+	"github.com/xorvus/scrap-chat/pkg/metrics"
 	"github.com/xorvus/scrap-chat/types"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"regexp"
@@ -23,6 +24,11 @@ import (
 	"unsafe"
 )
 
+const (
+	defaultBaseURL     = "https://www.youtube.com"
+	defaultSignalerURL = "https://signaler-pa.youtube.com"
+)
+
 const (
 	REG_FIRST_CHAT = `\[\[\d+,\[\[null,null,\["([^"]+)"\]\]\]\]`
 	REG_NO_CHAT    = `\[\[\d*,\[\[\[\[.*\[null,null,\["\d*`
@@ -36,8 +42,8 @@ var (
 	regChat          = regexp.MustCompile(REG_CHAT)
 	regSession       = regexp.MustCompile(REG_SESSION)
 	ytCfgRegex       = regexp.MustCompile(`ytcfg\.set\((\{.*?\})\);`)
+	playerRespRegex  = regexp.MustCompile(`(?s)ytInitialPlayerResponse\s*=\s*({.+?})\s*;\s*(?:var\s|</script>)`)
 	initialDataRegex = regexp.MustCompile(`(?s)(?:window\s*\[\s*["']ytInitialData["']\s*\]|ytInitialData)\s*=\s*({.+?})\s*;`)
-	ErrStreamNotLive = errors.New("stream not live")
 	bufferPool       = sync.Pool{
 		New: func() interface{} {
 			return bytes.NewBuffer(make([]byte, 0, 64*1024)) // Initial 64KB capacity
@@ -46,35 +52,239 @@ var (
 )
 
 type Youtube struct {
-	cookies                        []*http.Cookie
+	cookies      []*http.Cookie
+	httpClient   *http.Client
+	streamClient *http.Client
+	logger       *slog.Logger
+	userAgent    string
+	language     string
+	region       string
+	header       http.Header
+	cookieMu     sync.Mutex
+	cookieString string
+	ctx          context.Context
+	cancel       context.CancelFunc
+	chatView     types.ChatView
+	pacing       types.Pacing
+	buffer       types.BufferOptions
+	stats        outputStats
+	rateStats    *rateStats
+	metrics      *fetcherMetrics
+	baseURL      string
+	signalerURL  string
+	reconnect    *types.ReconnectPolicy
+	wait         bool
+	proxies      *ProxyPool
+}
+
+// liveSession holds the state of one capture, a Youtube fetcher can run
+// several of them at the same time.
+type liveSession struct {
+	*Youtube
+	// logger shadows the fetcher logger with the stream attribute.
+	logger *slog.Logger
+	// ctx carries the session proxy, it shadows the fetcher context.
+	// cancel stops this capture only.
+	ctx                            context.Context
+	cancel                         context.CancelFunc
+	end                            func(error)
+	output                         outputStats
 	config                         *types.YTCgf
 	continuation                   string
 	videoId                        string
 	gsessionID                     string
 	sid                            string
-	httpClient                     *http.Client
-	header                         http.Header
-	cookieString                   string
 	timeout                        int
 	isInvalidationContinuationData bool
 	session                        string
-	ctx                            *context.Context
-	verbose                        bool
+	watchURL                       string
+	authors                        recentAuthors
+	banners                        activeBanners
+	polls                          activePolls
+}
+
+func (y *Youtube) newSession() *liveSession {
+	ctx, cancel := context.WithCancel(y.sessionContext())
+	s := &liveSession{Youtube: y, ctx: ctx, cancel: cancel, logger: y.logger}
+	s.output.parent = &y.stats
+	return s
+}
+
+// sessionContext returns the fetcher context with a proxy of its own when a
+// pool is set, every request made with it goes through that proxy.
+func (y *Youtube) sessionContext() context.Context {
+	if y.proxies == nil {
+		return y.ctx
+	}
+	return y.proxies.withSessionProxy(y.ctx)
+}
+
+type YoutubeOptions struct {
+	// Verbose logs debug output to stderr when Logger is nil.
+	Verbose bool
+	// ChatView selects Top chat or Live chat, defaults to Live chat.
+	ChatView types.ChatView
+	// Pacing spreads live chat batches, defaults to smooth playback.
+	Pacing types.Pacing
+	// Buffer sizes the live chat channel and picks what happens when it is
+	// full, defaults to 100 messages and blocking.
+	Buffer types.BufferOptions
+	// Reconnect overrides types.DefaultReconnectPolicy.
+	Reconnect *types.ReconnectPolicy
+	// Wait keeps FetchLiveChat waiting for upcoming streams and premieres
+	// instead of failing with types.UpcomingError.
+	Wait bool
+	// HTTPClient replaces the default client, Timeout applies to every
+	// request except the long-poll stream.
+	HTTPClient *http.Client
+	Timeout    time.Duration
+	// Transport replaces the transport of HTTPClient. Every request goes
+	// through it.
+	Transport http.RoundTripper
+	// Proxies rotates the sessions over a proxy pool, it needs an
+	// *http.Transport.
+	Proxies *ProxyPool
+	// RateLimits overrides types.DefaultRateLimits, the zero value turns
+	// the limiter off.
+	RateLimits *types.RateLimits
+	// Logger receives the library logs, nothing is logged by default.
+	Logger *slog.Logger
+	// Metrics is the registry capture metrics are recorded in, defaults to
+	// metrics.Default.
+	Metrics *metrics.Registry
+	// BaseURL and SignalerURL replace https://www.youtube.com and
+	// https://signaler-pa.youtube.com, e.g. to run against a fake server.
+	BaseURL     string
+	SignalerURL string
+	// Language and Region are the InnerTube hl and gl, e.g. "en" and "US".
+	// Parsed texts such as membership months are only read in English.
+	Language  string
+	Region    string
+	UserAgent string
 }
 
-func NewYoutube(ctx *context.Context, verbose bool) *Youtube {
+func NewYoutube(ctx context.Context, opts YoutubeOptions) (*Youtube, error) {
+	if opts.ChatView == "" {
+		opts.ChatView = types.ChatViewLive
+	}
+	if opts.Pacing == "" {
+		opts.Pacing = types.PacingSmooth
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{}
+	}
+	if opts.Transport == nil {
+		opts.Transport = opts.HTTPClient.Transport
+	}
+	transport, err := newTransport(opts.Transport, opts.Proxies)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.DiscardHandler)
+		if opts.Verbose {
+			opts.Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		}
+	}
+	if opts.RateLimits == nil {
+		opts.RateLimits = &types.DefaultRateLimits
+	}
+	if opts.Metrics == nil {
+		opts.Metrics = metrics.Default
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = defaultBaseURL
+	}
+	if opts.SignalerURL == "" {
+		opts.SignalerURL = defaultSignalerURL
+	}
+	m := newFetcherMetrics(opts.Metrics)
+	ctx, cancel := context.WithCancel(ctx)
+
+	limited := &limitedTransport{
+		base:    transport,
+		limiter: sharedLimiter(*opts.RateLimits),
+		logger:  opts.Logger,
+		metrics: m,
+	}
+	client := *opts.HTTPClient
+	client.Transport = limited
+	streamClient := client
+	if opts.Timeout > 0 {
+		client.Timeout = opts.Timeout
+	}
+	streamClient.Timeout = 0
+
 	y := &Youtube{
-		httpClient: &http.Client{
-			Transport: &http.Transport{
-				MaxResponseHeaderBytes: 1 << 20,
-			},
-		},
-		ctx:     ctx,
-		verbose: verbose,
+		httpClient:   &client,
+		streamClient: &streamClient,
+		logger:       opts.Logger,
+		userAgent:    opts.UserAgent,
+		language:     opts.Language,
+		region:       opts.Region,
+		ctx:          ctx,
+		cancel:       cancel,
+		chatView:     opts.ChatView,
+		pacing:       opts.Pacing,
+		buffer:       opts.Buffer,
+		reconnect:    opts.Reconnect,
+		wait:         opts.Wait,
+		proxies:      opts.Proxies,
+		rateStats:    &limited.stats,
+		metrics:      m,
+		baseURL:      strings.TrimSuffix(opts.BaseURL, "/"),
+		signalerURL:  strings.TrimSuffix(opts.SignalerURL, "/"),
 	}
+	limited.headers = y.applyHeaders
 	y.header = make(http.Header)
 	defaultHeaders(y.header)
-	return y
+	if y.userAgent != "" {
+		y.header.Set("user-agent", y.userAgent)
+	}
+	if y.language != "" {
+		lang := y.language
+		if y.region != "" {
+			lang = fmt.Sprintf("%s-%s,%s;q=0.9", y.language, y.region, y.language)
+		}
+		y.header.Set("accept-language", lang)
+	}
+	return y, nil
+}
+
+// Stop cancels every capture started by this fetcher, in-flight requests are
+// aborted and the output channels are closed. The fetcher cannot be reused.
+func (y *Youtube) Stop() {
+	y.cancel()
+}
+
+// Stats reports the live chat output counters summed over every capture and
+// the time its requests spent waiting for the rate limiter. Each capture
+// reports its own counters too.
+func (y *Youtube) Stats() types.Stats {
+	stats := y.stats.snapshot()
+	stats.RateLimit = y.rateStats.snapshot()
+	return stats
+}
+
+// fail records err as the error that ended the capture, unless it was
+// stopped.
+func (s *liveSession) fail(ctx context.Context, err error) {
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+	s.end(err)
+}
+
+// sleepCtx waits for d, it returns false when ctx is done first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
 }
 
 func defaultHeaders(h http.Header) {
@@ -82,10 +292,10 @@ func defaultHeaders(h http.Header) {
 		"accept":                      "*/*",
 		"accept-language":             "en-US,en;q=0.9",
 		"cache-control":               "no-cache",
-		"origin":                      "https://www.youtube.com ",
+		"origin":                      "https://www.youtube.com",
 		"priority":                    "u=1, i",
 		"pragma":                      "no-cache",
-		"referer":                     "https://www.youtube.com/ ",
+		"referer":                     "https://www.youtube.com/",
 		"sec-ch-ua":                   "\"Chromium\";v=\"136\", \"Brave\";v=\"136\", \"Not.A/Brand\";v=\"99\"",
 		"sec-ch-ua-arch":              "\"arm\"",
 		"sec-ch-ua-bitness":           "\"64\"",
@@ -125,7 +335,7 @@ func (y *Youtube) AddCookies(path string) error {
 		}
 		timestamp, err := strconv.ParseInt(parts[4], 10, 64)
 		if err != nil {
-			log.Printf("Error parsing cookie time: %v", err)
+			y.logger.Warn("invalid cookie expiry", "cookie", parts[5], "err", err)
 			continue
 		}
 		cookies = append(cookies, &http.Cookie{
@@ -140,11 +350,29 @@ func (y *Youtube) AddCookies(path string) error {
 	if err := scanner.Err(); err != nil {
 		return fmt.Errorf("error reading cookie file: %w", err)
 	}
+	y.cookieMu.Lock()
 	y.cookies = cookies
 	y.cookieString = createCookieString(cookies)
+	y.cookieMu.Unlock()
 	return nil
 }
 
+// applyHeaders fills in the fetcher headers and cookies a request does not
+// set itself, every request goes through it.
+func (y *Youtube) applyHeaders(h http.Header) {
+	for k, vv := range y.header {
+		if _, ok := h[k]; !ok {
+			h[k] = append([]string(nil), vv...)
+		}
+	}
+	y.cookieMu.Lock()
+	cookie := y.cookieString
+	y.cookieMu.Unlock()
+	if cookie != "" && h.Get("Cookie") == "" {
+		h.Set("Cookie", cookie)
+	}
+}
+
 func createCookieString(cookies []*http.Cookie) string {
 	parts := make([]string, 0, len(cookies))
 	for _, c := range cookies {
@@ -157,72 +385,250 @@ func (y *Youtube) FetchVideoComments(path string, date *time.Time) (<-chan *type
 	return nil, nil
 }
 
-func (y *Youtube) FetchLiveChat(path string) (<-chan *types.LiveChatMessage, error) {
-	url := path
-	if strings.Contains(url, "@") {
-		info, err := y.FetchChannelInfo(path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch channel info: %w", err)
-		}
-		url = info.URL + "/live"
+func (y *Youtube) FetchLiveChat(path string) (*types.LiveChat, error) {
+	s := y.newSession()
+	capture, err := s.fetchLiveChat(path)
+	if err != nil {
+		s.cancel()
+		return nil, err
+	}
+	return capture, nil
+}
+
+func (s *liveSession) fetchLiveChat(path string) (*types.LiveChat, error) {
+	ctx := s.ctx
+
+	url, err := s.liveURL(ctx, path)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := y.getConfig(url); err != nil {
+	s.watchURL = url
+	page, err := s.getConfig(ctx, url)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
 
-	_, err := y.sendMessage(&MessageOptions{
-		"check", false, true,
-	})
+	if err := page.endErr(); err != nil {
+		return nil, err
+	}
+	if page.continuation == "" && !(s.wait && page.upcoming) {
+		if page.upcoming {
+			return nil, &types.UpcomingError{VideoID: page.videoID, ScheduledStart: page.scheduledStart}
+		}
+		return nil, types.ErrStreamNotLive
+	}
 
-	if err != nil {
+	b := newBackoff(s.reconnect, s.logger)
+	box := newOutbox(s.buffer, &s.output, s.logger)
+	capture, end := types.NewCapture(box.out, s.cancel, s.output.snapshot)
+	s.end = end
+
+	if s.wait && page.upcoming {
+		go func() {
+			defer s.cancel()
+			page, err := s.waitForStart(ctx, b, page)
+			if err != nil {
+				s.fail(ctx, err)
+				box.close()
+				return
+			}
+			s.runChat(ctx, b, box, !page.upcoming)
+		}()
+		return capture, nil
+	}
+
+	if err := s.startChat(ctx, b); err != nil {
+		if page.upcoming && errors.Is(err, types.ErrStreamNotLive) {
+			return nil, &types.UpcomingError{VideoID: page.videoID, ScheduledStart: page.scheduledStart}
+		}
 		return nil, err
 	}
 
-	if !y.isInvalidationContinuationData && y.timeout == 0 {
-		return nil, ErrStreamNotLive
+	go func() {
+		defer s.cancel()
+		s.runChat(ctx, b, box, !page.upcoming)
+	}()
+
+	return capture, nil
+}
+
+// startChat opens the chat session of the loaded watch page.
+func (s *liveSession) startChat(ctx context.Context, b *backoff) error {
+	if err := b.retry(ctx, func() error { return s.openSession(ctx) }); err != nil {
+		return err
 	}
 
-	//check use long poling
-	if y.isInvalidationContinuationData {
-		y.chooseServer()
-		y.getSID()
+	if !s.isInvalidationContinuationData && s.timeout == 0 {
+		return types.ErrStreamNotLive
 	}
+	return nil
+}
 
-	msg := make(chan *types.LiveChatMessage)
+// runChat streams the opened chat session into box and closes it at the end.
+// live is false for a waiting-room chat, the capture then only ends after the
+// stream went live.
+func (s *liveSession) runChat(ctx context.Context, b *backoff, box *outbox, live bool) {
+	defer box.close()
 
-	go func() {
-		defer close(msg)
+	s.metrics.activeStreams.Inc()
+	defer s.metrics.activeStreams.Dec()
 
-		y.streamChat(func(params []types.YTChatMessage) {
-			for _, param := range params {
-				userImage := ""
-				if len(param.Author.AuthorImages) > 0 {
-					userImage = param.Author.AuthorImages[0].URL
-				}
-				msg <- &types.LiveChatMessage{
-					ID:      param.ID,
-					Message: param.Message,
-					Author: types.Author{
-						ID:        param.Author.AuthorID,
-						Name:      param.Author.AuthorName,
-						Thumbnail: userImage,
-						URL:       fmt.Sprintf("https://youtube.com/channel/%s", param.Author.AuthorID),
-					},
-					Timestamp: param.Timestamp.Unix(),
-				}
+	// Batches already fetched are still delivered after the stream ends.
+	streamCtx, end := context.WithCancelCause(ctx)
+	defer end(nil)
+	go s.watchEnd(streamCtx, end, s.config.INNERTUBE_CONTEXT, s.videoId, live)
 
-				if !y.isInvalidationContinuationData {
-					time.Sleep(time.Duration(y.timeout/len(params)) * time.Millisecond)
-				} else {
-					time.Sleep(50 * time.Millisecond)
-				}
+	// Timeout requests and reconnects fetch some messages again.
+	seen := newDedupe(dedupeWindow, dedupeSize)
 
+	err := s.streamChat(streamCtx, b, func(params []types.YTChatMessage) {
+		params, late := seen.filter(params)
+		if late > 0 {
+			s.output.addLate(uint64(late))
+			s.logger.Debug("late messages", "count", late)
+		}
+		for i, param := range params {
+			queued, ok := box.send(ctx, toLiveChatMessage(param))
+			if queued {
+				s.metrics.delivered(param)
 			}
-		})
-	}()
+			if !ok {
+				return
+			}
+
+			if pause := s.pause(params, i); pause > 0 && !sleepCtx(ctx, pause) {
+				return
+			}
+		}
+	})
+	if ended := context.Cause(streamCtx); err == nil && errors.Is(ended, types.ErrStreamEnded) {
+		err = ended
+	}
+	switch {
+	case ctx.Err() != nil:
+	case errors.Is(err, types.ErrStreamEnded):
+		s.logger.Info("live chat ended", "err", err)
+	case err != nil:
+		s.logger.Error("live chat failed", "err", err)
+	}
+	s.fail(ctx, err)
+}
+
+// pause returns how long to wait after delivering params[i].
+func (s *liveSession) pause(params []types.YTChatMessage, i int) time.Duration {
+	switch s.pacing {
+	case types.PacingImmediate:
+		return 0
+	case types.PacingOriginal:
+		// Events stamped on arrival have no original gap. A batch never
+		// takes longer than the poll interval to play back.
+		if i+1 >= len(params) || !serverTimed(params[i]) || !serverTimed(params[i+1]) {
+			return 0
+		}
+		gap := params[i+1].Timestamp.Sub(params[i].Timestamp)
+		return min(max(gap, 0), time.Duration(s.timeout)*time.Millisecond)
+	}
 
-	return msg, nil
+	if s.isInvalidationContinuationData {
+		return 50 * time.Millisecond
+	}
+	return time.Duration(s.timeout/len(params)) * time.Millisecond
+}
+
+// openSession checks the chat continuation and, for long-poll chats, opens a
+// signaler session.
+func (s *liveSession) openSession(ctx context.Context) error {
+	_, err := s.sendMessage(ctx, &MessageOptions{
+		"check", false, true,
+	})
+	if err != nil {
+		return err
+	}
+
+	//check use long poling
+	if s.isInvalidationContinuationData {
+		return s.openSignaler(ctx)
+	}
+	return nil
+}
+
+func (s *liveSession) openSignaler(ctx context.Context) error {
+	if err := s.chooseServer(ctx); err != nil {
+		return err
+	}
+	return s.getSID(ctx)
+}
+
+// resume rebuilds the session cause invalidated. A rejected InnerTube request
+// reloads the watch page for a fresh continuation, a rejected signaler
+// session is reopened.
+func (s *liveSession) resume(ctx context.Context, cause error) error {
+	var fe *types.FetchError
+	if !errors.As(cause, &fe) || fe.Kind != types.ErrorSessionRejected {
+		return nil
+	}
+	if fe.Op != "sendMessage" {
+		return s.openSignaler(ctx)
+	}
+	page, err := s.getConfig(ctx, s.watchURL)
+	if err != nil {
+		return err
+	}
+	if err := page.endErr(); err != nil {
+		return err
+	}
+	return s.openSession(ctx)
+}
+
+// liveURL resolves a channel handle to its /live page.
+func (y *Youtube) liveURL(ctx context.Context, path string) (string, error) {
+	if !strings.Contains(path, "@") {
+		if !strings.HasPrefix(path, "http") {
+			return y.baseURL + "/watch?v=" + path, nil
+		}
+		return path, nil
+	}
+	info, err := y.fetchChannelInfo(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch channel info: %w", err)
+	}
+	return info.URL + "/live", nil
+}
+
+func toLiveChatMessage(param types.YTChatMessage) *types.LiveChatMessage {
+	return &types.LiveChatMessage{
+		ID:         param.ID,
+		Type:       param.Type,
+		Message:    param.Message,
+		Segments:   param.Segments,
+		Author:     toAuthor(param.Author),
+		Timestamp:  param.Timestamp.Unix(),
+		Paid:       param.Paid,
+		Membership: param.Membership,
+		Moderation: param.Moderation,
+		Banner:     param.Banner,
+		Poll:       param.Poll,
+	}
+}
+
+func toAuthor(author types.YTAuthor) types.Author {
+	userImage := ""
+	if len(author.AuthorImages) > 0 {
+		userImage = author.AuthorImages[0].URL
+	}
+	channelURL := ""
+	if author.AuthorID != "" {
+		channelURL = fmt.Sprintf("https://youtube.com/channel/%s", author.AuthorID)
+	}
+	result := types.Author{
+		ID:        author.AuthorID,
+		Name:      author.AuthorName,
+		Thumbnail: userImage,
+		URL:       channelURL,
+	}
+	applyBadges(&result, author.AuthorBadges)
+	return result
 }
 
 func (y *Youtube) FetchLive() {
@@ -230,14 +636,25 @@ func (y *Youtube) FetchLive() {
 }
 
 func (y *Youtube) FetchChannelInfo(path string) (*types.ChannelInfo, error) {
+	return y.fetchChannelInfo(y.sessionContext(), path)
+}
+
+func (y *Youtube) fetchChannelInfo(ctx context.Context, path string) (*types.ChannelInfo, error) {
 	if !strings.HasPrefix(path, "http") && strings.Contains(path, "@") {
-		path = "https://www.youtube.com/" + path
+		path = y.baseURL + "/" + path
 	}
 
 	info := &types.ChannelInfo{}
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(path)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", path, nil)
+	if err != nil {
+		return &types.ChannelInfo{}, err
+	}
+
+	resp, err := y.httpClient.Do(req)
 	if err != nil {
 		return &types.ChannelInfo{}, err
 	}
@@ -270,20 +687,64 @@ func (y *Youtube) FetchChannelInfo(path string) (*types.ChannelInfo, error) {
 	return info, nil
 }
 
-func (y *Youtube) getConfig(url string) error {
-	client := &http.Client{
-		Transport: &http.Transport{
-			DisableKeepAlives: true,
-		},
+type watchPage struct {
+	config       *types.YTCgf
+	continuation string
+	videoID      string
+	// offline is set when the player shows the offline slate, ended when the
+	// broadcast has an end time.
+	offline        bool
+	upcoming       bool
+	ended          bool
+	scheduledStart time.Time
+}
+
+// endErr returns a StreamEndedError when the page shows a finished broadcast.
+func (p *watchPage) endErr() error {
+	if p.ended || (p.offline && !p.upcoming) {
+		return &types.StreamEndedError{VideoID: p.videoID, Reason: types.EndOfflineSlate}
+	}
+	return nil
+}
+
+func (s *liveSession) getConfig(ctx context.Context, url string) (*watchPage, error) {
+	page, err := s.loadWatchPage(ctx, url, s.chatView)
+	if err != nil {
+		return nil, err
 	}
 
-	resp, err := client.Get(url)
+	s.config = page.config
+	s.continuation = page.continuation
+	if s.videoId != page.videoID {
+		s.videoId = page.videoID
+		s.logger = s.Youtube.logger.With("stream", page.videoID)
+	}
+
+	return page, nil
+}
+
+// loadWatchPage reads ytcfg and ytInitialData from a watch page without
+// touching the chat state. An empty view skips the chat continuation lookup.
+func (y *Youtube) loadWatchPage(ctx context.Context, url string, view types.ChatView) (*watchPage, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Errorf("error visiting URL: %w", err)
+		return nil, fmt.Errorf("error visiting URL: %w", err)
+	}
+	// The chat view titles are matched in English, the language option
+	// only applies to InnerTube.
+	req.Header.Set("accept-language", "en-US,en;q=0.9")
+
+	resp, err := y.httpClient.Do(req)
+	if err != nil {
+		return nil, fetchError("loadWatchPage", types.ErrorNetwork, err)
 	}
 
 	defer resp.Body.Close()
 
+	if err := checkStatus("loadWatchPage", resp); err != nil {
+		return nil, err
+	}
+
 	const maxBytes = 2 << 20 // 2MB
 	limited := io.LimitReader(resp.Body, maxBytes)
 	buffer := bufferPool.Get().(*bytes.Buffer)
@@ -296,37 +757,47 @@ func (y *Youtube) getConfig(url string) error {
 	}()
 
 	foundCfg := false
+	foundPlayer := false
 	foundInitial := false
 	config := &types.YTCgf{}
+	page := &watchPage{}
 
 	for {
-		n, err := limited.Read(chunk[:])
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return err
-		}
-
+		// A read can return the last bytes of the page along with io.EOF.
+		n, readErr := limited.Read(chunk[:])
 		buffer.Write(chunk[:n])
 
 		if !foundCfg {
-			foundCfg = processConfigRegex(buffer, ytCfgRegex, config)
+			foundCfg = processConfigRegex(buffer, ytCfgRegex, config, y.logger, y.metrics)
+		}
+
+		if !foundPlayer {
+			foundPlayer = processPlayerResponseRegex(buffer, playerRespRegex, page)
 		}
 
 		if !foundInitial {
-			foundInt, cont, vid := processInitialDataRegex(buffer, initialDataRegex)
-			y.continuation = strings.Clone(cont)
-			y.videoId = strings.Clone(vid)
+			foundInt, cont, vid, err := processInitialDataRegex(buffer, initialDataRegex, view)
+			if err != nil {
+				return nil, err
+			}
+			page.continuation = strings.Clone(cont)
+			page.videoID = strings.Clone(vid)
 			foundInitial = foundInt
 		}
 
-		if foundCfg && foundInitial {
+		if foundCfg && foundPlayer && foundInitial {
 			break
 		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
 	}
 
-	y.config = &types.YTCgf{
+	page.config = &types.YTCgf{
 		INNERTUBE_API_KEY:        config.INNERTUBE_API_KEY,
 		API_KEY:                  config.API_KEY,
 		INNERTUBE_CONTEXT:        config.INNERTUBE_CONTEXT,
@@ -336,10 +807,17 @@ func (y *Youtube) getConfig(url string) error {
 
 	config = nil
 
-	return nil
+	if y.language != "" {
+		page.config.INNERTUBE_CONTEXT.Client.Hl = y.language
+	}
+	if y.region != "" {
+		page.config.INNERTUBE_CONTEXT.Client.Gl = y.region
+	}
+
+	return page, nil
 }
 
-func processConfigRegex(buffer *bytes.Buffer, regex *regexp.Regexp, config *types.YTCgf) bool {
+func processConfigRegex(buffer *bytes.Buffer, regex *regexp.Regexp, config *types.YTCgf, logger *slog.Logger, m *fetcherMetrics) bool {
 	data := buffer.Bytes()
 	match := regex.FindSubmatch(data)
 	if len(match) < 2 {
@@ -354,94 +832,166 @@ func processConfigRegex(buffer *bytes.Buffer, regex *regexp.Regexp, config *type
 	config.ID_TOKEN = gjson.Get(jsonStr, "ID_TOKEN").String()
 	contextJson := gjson.Get(jsonStr, "INNERTUBE_CONTEXT").Raw
 	if err := json.Unmarshal([]byte(contextJson), &config.INNERTUBE_CONTEXT); err != nil {
-		log.Printf("Error parsing INNERTUBE_CONTEXT: %v", err)
+		logger.Warn("invalid INNERTUBE_CONTEXT", "endpoint", "loadWatchPage", "err", err)
+		m.parseFailure("loadWatchPage")
+		return false
+	}
+
+	return true
+}
+
+func processPlayerResponseRegex(buffer *bytes.Buffer, regex *regexp.Regexp, page *watchPage) bool {
+	data := buffer.Bytes()
+	match := regex.FindSubmatch(data)
+	if len(match) < 2 {
 		return false
 	}
 
+	jsonBytes := match[1]
+	jsonStr := *(*string)(unsafe.Pointer(&jsonBytes))
+	page.offline = gjson.Get(jsonStr, "playabilityStatus.liveStreamability.liveStreamabilityRenderer.offlineSlate").Exists()
+	page.upcoming = gjson.Get(jsonStr, "videoDetails.isUpcoming").Bool()
+	if start := gjson.Get(jsonStr, "playabilityStatus.liveStreamability.liveStreamabilityRenderer.offlineSlate.liveStreamOfflineSlateRenderer.scheduledStartTime").Int(); start > 0 {
+		page.scheduledStart = time.Unix(start, 0)
+	}
+	page.ended = gjson.Get(jsonStr, "microformat.playerMicroformatRenderer.liveBroadcastDetails.endTimestamp").Exists()
+
 	return true
 }
 
-func processInitialDataRegex(buffer *bytes.Buffer, regex *regexp.Regexp) (bool, string, string) {
+func processInitialDataRegex(buffer *bytes.Buffer, regex *regexp.Regexp, view types.ChatView) (bool, string, string, error) {
 	data := buffer.Bytes()
 	match := regex.FindSubmatch(data)
 	if len(match) < 2 {
-		return false, "", ""
+		return false, "", "", nil
 	}
 
 	jsonBytes := match[1]
 	jsonStr := *(*string)(unsafe.Pointer(&jsonBytes))
 
-	continuationStr := gjson.Get(jsonStr, "contents.twoColumnWatchNextResults.conversationBar.liveChatRenderer.header.liveChatHeaderRenderer.viewSelector.sortFilterSubMenuRenderer.subMenuItems.1.continuation.reloadContinuationData.continuation").String()
+	continuationStr := ""
+	if view != "" {
+		cont, err := chatViewContinuation(jsonStr, view)
+		if err != nil {
+			return false, "", "", err
+		}
+		continuationStr = cont
+	}
 	videoIdStr := gjson.Get(jsonStr, "currentVideoEndpoint.watchEndpoint.videoId").String()
 
-	return true, continuationStr, videoIdStr
+	return true, continuationStr, videoIdStr, nil
 }
 
-func (y *Youtube) streamChat(param func([]types.YTChatMessage)) {
-	if y.isInvalidationContinuationData {
-		lastTime := time.Now().Unix()
-		y.longPooling(func(res string) {
-			tempTime := time.Now()
-			diff := tempTime.Sub(time.Unix(lastTime, 0))
+// chatViewContinuation picks the view selector item by its title ("Top chat",
+// "Live chat", with a " replay" suffix on VODs) instead of its position. An
+// empty continuation means the video has no chat at all.
+func chatViewContinuation(jsonStr string, view types.ChatView) (string, error) {
+	liveChat := gjson.Get(jsonStr, "contents.twoColumnWatchNextResults.conversationBar.liveChatRenderer")
+	if !liveChat.Exists() {
+		return "", nil
+	}
 
-			switch {
-			case IsRegexTrue(regFirstChat, res):
-				go func() {
-					_, match := RegexGetValue(regSession, res)
-					if len(match) == 0 {
-						log.Printf("Regex match for session failed. Response: %s\n", res)
-						return
-					}
-					y.session = match[0]
-				}()
-
-				res, _ := y.sendMessage(&MessageOptions{
-					Timestamp: "",
-					IsTimeout: false,
-					IsFirst:   true,
-				})
-				param(res)
-			case diff >= 10*time.Second:
-				res, _ := y.sendMessage(&MessageOptions{
-					Timestamp: "",
-					IsTimeout: true,
-					IsFirst:   false,
-				})
-				param(res)
-			case IsRegexTrue(regNoChat, res):
-				// No chat, do nothing
-			case func() bool {
-				ok, match := RegexGetValue(regChat, res)
-				if ok {
-					res, _ := y.sendMessage(&MessageOptions{
-						Timestamp: match[0],
-						IsTimeout: false,
-						IsFirst:   false,
-					})
-					param(res)
-				}
-				return ok
-			}():
-				// handled in the inline func
-			default:
-				log.Printf("[%dms] Undefined: %s", diff/time.Millisecond, res)
+	items := liveChat.Get("header.liveChatHeaderRenderer.viewSelector.sortFilterSubMenuRenderer.subMenuItems").Array()
+	for _, item := range items {
+		title := strings.ToLower(item.Get("title").String())
+		if strings.HasPrefix(title, string(view)+" chat") {
+			return item.Get("continuation.reloadContinuationData.continuation").String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s chat", types.ErrChatViewUnavailable, view)
+}
+
+// streamChat feeds chat batches to param, one at a time, until ctx is done. Failures are
+// retried following the reconnect policy, the error that finally stops the
+// capture is returned.
+func (s *liveSession) streamChat(ctx context.Context, b *backoff, param func([]types.YTChatMessage)) error {
+	for {
+		var cause error
+		if s.isInvalidationContinuationData {
+			cause = s.streamInvalidation(ctx, b, param)
+		} else {
+			cause = s.streamTimed(ctx, b, param)
+		}
+		if cause == nil || ctx.Err() != nil {
+			return nil
+		}
+
+		// Only a delivered batch resets b, resume succeeds right away after
+		// a network failure.
+		for err := cause; err != nil; err = s.resume(ctx, cause) {
+			if !b.wait(ctx, err) {
+				return err
+			}
+		}
+		if s.isInvalidationContinuationData {
+			s.metrics.reconnects.Inc()
+		}
+	}
+}
+
+func (s *liveSession) streamInvalidation(ctx context.Context, b *backoff, param func([]types.YTChatMessage)) error {
+	lastTime := time.Now().Unix()
+	return s.longPooling(ctx, func(res string) error {
+		tempTime := time.Now()
+		diff := tempTime.Sub(time.Unix(lastTime, 0))
+
+		var opts *MessageOptions
+		switch {
+		case IsRegexTrue(regFirstChat, res):
+			if _, match := RegexGetValue(regSession, res); len(match) > 0 {
+				s.session = match[0]
+			} else {
+				s.logger.Warn("no session in first chat event", "session", s.gsessionID, "response", res)
 			}
+			opts = &MessageOptions{IsFirst: true}
+		case diff >= 10*time.Second:
+			opts = &MessageOptions{IsTimeout: true}
+		case IsRegexTrue(regNoChat, res):
+			// No chat, do nothing
+		default:
+			if ok, match := RegexGetValue(regChat, res); ok {
+				opts = &MessageOptions{Timestamp: match[0]}
+			} else {
+				s.logger.Debug("unknown signaler event", "session", s.gsessionID, "since_last", diff, "response", res)
+				s.metrics.parseFailure("longPooling")
+			}
+		}
+
+		lastTime = tempTime.Unix()
+		if opts == nil {
+			return nil
+		}
 
-			lastTime = tempTime.Unix()
+		batch, err := s.sendMessage(ctx, opts)
+		if err != nil {
+			return err
+		}
+		b.reset()
+		param(batch)
+		return nil
+	})
+}
+
+// streamTimed polls every timeout. A batch is delivered before the next poll,
+// the time param takes counts towards the wait.
+func (s *liveSession) streamTimed(ctx context.Context, b *backoff, param func([]types.YTChatMessage)) error {
+	wait := time.Duration(s.timeout) * time.Millisecond
+	for sleepCtx(ctx, wait) {
+		polled := time.Now()
+		res, err := s.sendMessage(ctx, &MessageOptions{
+			Timestamp: "",
+			IsTimeout: false,
+			IsFirst:   true,
 		})
-	} else {
-		for {
-			time.Sleep(time.Duration(y.timeout) * time.Millisecond)
-			res, _ := y.sendMessage(&MessageOptions{
-				Timestamp: "",
-				IsTimeout: false,
-				IsFirst:   true,
-			})
-			go func() {
-				param(res)
-			}()
+		if err != nil {
+			return err
 		}
+		b.reset()
+		param(res)
+		wait = time.Duration(s.timeout)*time.Millisecond - time.Since(polled)
 	}
+	return nil
 }
 
 // IsRegexTrue returns true if the regex matches the data.
@@ -458,51 +1008,47 @@ func RegexGetValue(re *regexp.Regexp, data string) (bool, []string) {
 	return false, nil
 }
 
-func (y *Youtube) copyHeaders(req *http.Request, h http.Header) {
-	for k, vv := range h {
-		for _, v := range vv {
-			req.Header.Add(k, v)
-		}
-	}
-	req.Header.Set("Cookie", y.cookieString)
-}
-
-func (y *Youtube) longPooling(param func(string)) {
-	if y.verbose {
-		log.Println("Long pool...")
-	}
+func (s *liveSession) longPooling(ctx context.Context, param func(string) error) error {
+	s.logger.Debug("long poll started", "session", s.gsessionID)
 	commentCount := 0
 	for {
-		url := fmt.Sprintf("https://signaler-pa.youtube.com/punctual/multi-watch/channel?VER=8&gsessionid=%s&key=%s&RID=rpc&SID=%s&AID=0&CI=0&TYPE=xmlhttp&zx=%s&t=1",
-			y.gsessionID, y.config.API_KEY, y.sid, utils.GenerateZX())
+		url := fmt.Sprintf("%s/punctual/multi-watch/channel?VER=8&gsessionid=%s&key=%s&RID=rpc&SID=%s&AID=0&CI=0&TYPE=xmlhttp&zx=%s&t=1",
+			s.signalerURL, s.gsessionID, s.config.API_KEY, s.sid, utils.GenerateZX())
 
-		req, err := http.NewRequest("GET", url, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
-			log.Printf("Request error: %v", err)
-			return
+			return fetchError("longPooling", types.ErrorProtocol, err)
 		}
 
-		y.copyHeaders(req, y.header)
-
-		resp, err := y.httpClient.Do(req)
+		connected := time.Now()
+		resp, err := s.streamClient.Do(req)
 		if err != nil {
-			log.Printf("HTTP error: %v", err)
-			return
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fetchError("longPooling", types.ErrorNetwork, err)
 		}
-		defer resp.Body.Close()
-		if y.verbose {
-			log.Println("Connected, streaming...")
+		if err := checkStatus("longPooling", resp); err != nil {
+			resp.Body.Close()
+			// The signaler answers 400 for an unknown or expired SID.
+			if resp.StatusCode == http.StatusBadRequest {
+				err.(*types.FetchError).Kind = types.ErrorSessionRejected
+			}
+			return err
 		}
+		s.logger.Debug("long poll connected", "session", s.gsessionID, "latency", time.Since(connected))
 		reader := bufio.NewReader(resp.Body)
 
 		lastTime := time.Now().Unix()
 		for {
 			line, err := reader.ReadString('\n')
 			if err != nil {
-				if err == io.EOF {
-					log.Println("Stream closed by server.")
-				} else {
-					log.Printf("Error reading stream: %v", err)
+				switch {
+				case ctx.Err() != nil:
+				case err == io.EOF:
+					s.logger.Debug("long poll closed by server", "session", s.gsessionID, "duration", time.Since(connected))
+				default:
+					s.logger.Warn("long poll read failed", "session", s.gsessionID, "err", err)
 				}
 				break
 			}
@@ -511,98 +1057,107 @@ func (y *Youtube) longPooling(param func(string)) {
 				continue
 			}
 
-			if y.verbose {
-				log.Println(line)
+			s.logger.Debug("signaler event", "session", s.gsessionID, "line", line)
+			if err := param(line); err != nil {
+				resp.Body.Close()
+				return err
 			}
-			param(line)
 			tempTime := time.Now()
 			diff := tempTime.Sub(time.Unix(lastTime, 0))
 			if diff > 4*time.Minute {
-				if y.verbose {
-					log.Println("Refersh.....")
+				s.logger.Debug("refreshing credentials", "session", s.gsessionID)
+				if err := s.refreshCreds(ctx); err != nil {
+					if !isTransient(err) {
+						resp.Body.Close()
+						return err
+					}
+					s.logger.Warn("refresh credentials failed", "session", s.gsessionID, "endpoint", "refreshCreds", "err", err)
 				}
-				y.refreshCreds()
 				lastTime = time.Now().Unix()
 				commentCount += 1
 			}
 
 			if commentCount >= 4 {
-				if y.verbose {
-					log.Println("Reset SID...")
+				s.logger.Debug("resetting SID", "session", s.gsessionID)
+				if err := s.getSID(ctx); err != nil {
+					resp.Body.Close()
+					return err
 				}
-				y.getSID()
 				commentCount = 0
 				break
 			}
 		}
-		if y.verbose {
-			log.Println("Reconnecting...")
+		resp.Body.Close()
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.logger.Debug("long poll reconnecting", "session", s.gsessionID)
+		s.metrics.reconnects.Inc()
+		if !sleepCtx(ctx, 500*time.Millisecond) {
+			return nil
 		}
-		time.Sleep(500 * time.Millisecond)
 	}
 }
 
-func (y *Youtube) refreshCreds() {
-	url := fmt.Sprintf("https://signaler-pa.youtube.com/punctual/v1/refreshCreds?key=%s&gsessionid=%s",
-		y.config.API_KEY, y.gsessionID)
-	payloadRaw := fmt.Sprintf("[\"%s\"]", y.session)
+func (s *liveSession) refreshCreds(ctx context.Context) error {
+	s.metrics.signaler.With("refreshCreds").Inc()
+	url := fmt.Sprintf("%s/punctual/v1/refreshCreds?key=%s&gsessionid=%s",
+		s.signalerURL, s.config.API_KEY, s.gsessionID)
+	payloadRaw := fmt.Sprintf("[\"%s\"]", s.session)
 	payload := strings.NewReader(payloadRaw)
-	req, err := http.NewRequest("POST", url, payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, payload)
 	if err != nil {
-		log.Printf("refresh creds error: %v", err)
-		return
+		return fetchError("refreshCreds", types.ErrorProtocol, err)
 	}
 
-	y.copyHeaders(req, y.header)
 	req.Header.Set("content-type", "application/json+protobuf")
 
-	resp, err := y.httpClient.Do(req)
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		log.Printf("refresh creds HTTP error: %v", err)
-		return
-	}
-	if y.verbose {
-		log.Printf("Refersh: %d\n", resp.StatusCode)
+		return fetchError("refreshCreds", types.ErrorNetwork, err)
 	}
+	defer resp.Body.Close()
 
-	resp.Body.Close()
+	s.logger.Debug("credentials refreshed", "session", s.gsessionID, "status", resp.StatusCode)
 
+	return checkStatus("refreshCreds", resp)
 }
 
-func (y *Youtube) getSID() {
-	url := fmt.Sprintf("https://signaler-pa.youtube.com/punctual/multi-watch/channel?VER=8&gsessionid=%s&key=%s&RID=6167&CVER=22&zx=%s&t=1",
-		y.gsessionID, y.config.API_KEY, utils.GenerateZX())
-	payloadRaw := fmt.Sprintf("count=1&ofs=0&req0___data__=[[[\"1\",[null,null,null,[9,5],null,[[\"youtube_live_chat_web\"],[1],[[[\"chat~%s\"]]]],null,null,1],null,3]]]", y.videoId)
+func (s *liveSession) getSID(ctx context.Context) error {
+	s.metrics.signaler.With("getSID").Inc()
+	url := fmt.Sprintf("%s/punctual/multi-watch/channel?VER=8&gsessionid=%s&key=%s&RID=6167&CVER=22&zx=%s&t=1",
+		s.signalerURL, s.gsessionID, s.config.API_KEY, utils.GenerateZX())
+	payloadRaw := fmt.Sprintf("count=1&ofs=0&req0___data__=[[[\"1\",[null,null,null,[9,5],null,[[\"youtube_live_chat_web\"],[1],[[[\"chat~%s\"]]]],null,null,1],null,3]]]", s.videoId)
 	payload := strings.NewReader(payloadRaw)
 
-	req, err := http.NewRequest("POST", url, payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, payload)
 	if err != nil {
-		log.Printf("getSID request error: %v", err)
-		return
+		return fetchError("getSID", types.ErrorProtocol, err)
 	}
 
-	y.copyHeaders(req, y.header)
 	req.Header.Set("content-type", "application/x-www-form-urlencoded")
 	req.Header.Set("x-webchannel-content-type", "application/json+protobuf")
 
-	resp, err := y.httpClient.Do(req)
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		log.Printf("getSID HTTP error: %v", err)
-		return
+		return fetchError("getSID", types.ErrorNetwork, err)
 	}
 	defer resp.Body.Close()
 
+	if err := checkStatus("getSID", resp); err != nil {
+		return err
+	}
+
 	limited := io.LimitReader(resp.Body, 1<<20)
 	body, err := io.ReadAll(limited)
 	if err != nil {
-		log.Printf("getSID read error: %v", err)
-		return
+		return fetchError("getSID", types.ErrorNetwork, err)
 	}
 
 	idx := bytes.Index(body, []byte("[["))
 	if idx == -1 {
-		log.Println("getSID: JSON array not found")
-		return
+		return s.parseError("getSID", errors.New("JSON array not found"))
 	}
 
 	jsonPart := make([]byte, len(body)-idx)
@@ -612,19 +1167,16 @@ func (y *Youtube) getSID() {
 
 	t, err := decoder.Token()
 	if err != nil {
-		log.Printf("getSID JSON decode error: %v", err)
-		return
+		return s.parseError("getSID", err)
 	}
 	if delim, ok := t.(json.Delim); !ok || delim != '[' {
-		log.Println("getSID: expected top-level array")
-		return
+		return s.parseError("getSID", errors.New("expected top-level array"))
 	}
 
 	for decoder.More() {
 		var elem []interface{}
 		if err := decoder.Decode(&elem); err != nil {
-			log.Printf("getSID elem decode error: %v", err)
-			return
+			return s.parseError("getSID", err)
 		}
 		if len(elem) < 2 {
 			continue
@@ -634,50 +1186,51 @@ func (y *Youtube) getSID() {
 			continue
 		}
 		if sid, ok := innerArray[1].(string); ok {
-			y.sid = sid
-			return
+			s.sid = sid
+			return nil
 		}
 	}
 
-	log.Println("getSID: SID not found in the JSON structure")
+	return fetchError("getSID", types.ErrorSessionRejected, errors.New("SID not found in the JSON structure"))
 }
 
-func (y *Youtube) chooseServer() {
-	url := fmt.Sprintf("https://signaler-pa.youtube.com/punctual/v1/chooseServer?key=%s", y.config.API_KEY)
-	rawPayload := fmt.Sprintf("[[null,null,null,[9,5],null,[[\"youtube_live_chat_web\"],[1],[[[\"chat~%s\"]]]]],null,null,0]", y.videoId)
+func (s *liveSession) chooseServer(ctx context.Context) error {
+	s.metrics.signaler.With("chooseServer").Inc()
+	url := fmt.Sprintf("%s/punctual/v1/chooseServer?key=%s", s.signalerURL, s.config.API_KEY)
+	rawPayload := fmt.Sprintf("[[null,null,null,[9,5],null,[[\"youtube_live_chat_web\"],[1],[[[\"chat~%s\"]]]]],null,null,0]", s.videoId)
 	payload := strings.NewReader(rawPayload)
 
-	req, err := http.NewRequest("POST", url, payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, payload)
 	if err != nil {
-		log.Printf("chooseServer request error: %v", err)
-		return
+		return fetchError("chooseServer", types.ErrorProtocol, err)
 	}
 
-	y.copyHeaders(req, y.header)
 	req.Header.Set("content-type", "application/json+protobuf")
 
-	resp, err := y.httpClient.Do(req)
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		log.Printf("chooseServer HTTP error: %v", err)
-		return
+		return fetchError("chooseServer", types.ErrorNetwork, err)
 	}
 	defer resp.Body.Close()
 
+	if err := checkStatus("chooseServer", resp); err != nil {
+		return err
+	}
+
 	decoder := json.NewDecoder(resp.Body)
 	var result []interface{}
 	if err := decoder.Decode(&result); err != nil {
-		log.Printf("getSID: decode error: %v", err)
-		return
+		return s.parseError("chooseServer", err)
 	}
 
 	if len(result) > 0 {
-		gsessionID, ok := result[0].(string)
-		if ok {
-			y.gsessionID = gsessionID
-		} else {
-			log.Println("chooseServer: gsessionid is not a string")
+		if gsessionID, ok := result[0].(string); ok {
+			s.gsessionID = gsessionID
+			return nil
 		}
 	}
+
+	return fetchError("chooseServer", types.ErrorSessionRejected, errors.New("gsessionid is not a string"))
 }
 
 type MessageOptions struct {
@@ -686,12 +1239,12 @@ type MessageOptions struct {
 	IsFirst   bool
 }
 
-func (y *Youtube) sendMessage(opts *MessageOptions) ([]types.YTChatMessage, error) {
-	url := "https://www.youtube.com/youtubei/v1/live_chat/get_live_chat?prettyPrint=false"
+func (s *liveSession) sendMessage(ctx context.Context, opts *MessageOptions) ([]types.YTChatMessage, error) {
+	url := s.baseURL + "/youtubei/v1/live_chat/get_live_chat?prettyPrint=false"
 
 	ytPayloadMessageLive := types.YTPayloadMessageLive{
-		Context:      y.config.INNERTUBE_CONTEXT,
-		Continuation: y.continuation,
+		Context:      s.config.INNERTUBE_CONTEXT,
+		Continuation: s.continuation,
 		WebClientInfo: types.YTWebClientInfo{
 			IsDocumentHidden: false,
 		},
@@ -704,36 +1257,41 @@ func (y *Youtube) sendMessage(opts *MessageOptions) ([]types.YTChatMessage, erro
 		ytPayloadMessageLive.InvalidationPayloadLastPublishAtUsec = &opts.Timestamp
 	}
 
+	// The buffer backs the request body, it goes back to the pool only
+	// after the request is done.
 	buf := bufferPool.Get().(*bytes.Buffer)
 	buf.Reset()
+	defer bufferPool.Put(buf)
 
 	encoder := json.NewEncoder(buf)
 	if err := encoder.Encode(ytPayloadMessageLive); err != nil {
-		return nil, fmt.Errorf("sendMessage: marshal error: %w", err)
+		return nil, fetchError("sendMessage", types.ErrorProtocol, err)
 	}
 
-	req, err := http.NewRequest("POST", url, buf)
-	bufferPool.Put(buf)
-
+	req, err := http.NewRequestWithContext(ctx, "POST", url, buf)
 	if err != nil {
-		return nil, fmt.Errorf("sendMessage: request error: %w", err)
+		return nil, fetchError("sendMessage", types.ErrorProtocol, err)
 	}
 
-	res, err := y.httpClient.Do(req)
+	res, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("sendMessage: HTTP error: %w", err)
+		return nil, fetchError("sendMessage", types.ErrorNetwork, err)
 	}
 	defer res.Body.Close()
 
+	if err := checkStatus("sendMessage", res); err != nil {
+		return nil, err
+	}
+
 	var chatMsgResp types.YTChatMessagesResponse
 	decoder := json.NewDecoder(res.Body)
 	if err := decoder.Decode(&chatMsgResp); err != nil {
-		return nil, fmt.Errorf("sendMessage: unmarshal error: %w", err)
+		return nil, s.parseError("sendMessage", err)
 	}
 
 	continuations := chatMsgResp.ContinuationContents.LiveChatContinuation.Continuations
 	if len(continuations) == 0 {
-		return nil, fmt.Errorf("sendMessage: no continuation data available")
+		return nil, &types.StreamEndedError{VideoID: s.videoId, Reason: types.EndNoContinuation}
 	}
 
 	cont := continuations[0]
@@ -741,70 +1299,25 @@ func (y *Youtube) sendMessage(opts *MessageOptions) ([]types.YTChatMessage, erro
 	switch {
 	case cont.InvalidationContinuationData != nil:
 		data := cont.InvalidationContinuationData
-		y.timeout = data.TimeoutMs
+		s.timeout = data.TimeoutMs
 		if opts.Timestamp != "check" {
-			y.continuation = data.Continuation
+			s.continuation = data.Continuation
 		}
-		y.isInvalidationContinuationData = true
+		s.isInvalidationContinuationData = true
+		s.metrics.polls.With("invalidation").Inc()
 
 	case cont.TimedContinuationData != nil:
 		data := cont.TimedContinuationData
-		y.timeout = data.TimeoutMs
-		y.continuation = data.Continuation
-		y.isInvalidationContinuationData = false
+		s.timeout = data.TimeoutMs
+		s.continuation = data.Continuation
+		s.isInvalidationContinuationData = false
+		s.metrics.polls.With("timed").Inc()
 
 	default:
-		return nil, fmt.Errorf("sendMessage: no known continuation data type found")
-	}
-
-	actions := chatMsgResp.ContinuationContents.LiveChatContinuation.Actions
-	chatMessages := make([]types.YTChatMessage, 0, len(actions))
-
-	var textBuilder strings.Builder
-	const avgMessageSize = 128
-	thumbnailsBuffer := make([]string, 0, 2)
-
-	for _, action := range actions {
-		renderer := action.AddChatItemAction.Item.LiveChatTextMessageRenderer
-		if len(renderer.Message.Runs) == 0 {
-			continue
-		}
-
-		textBuilder.Reset()
-		thumbnailsBuffer = thumbnailsBuffer[:0]
-		textBuilder.Grow(avgMessageSize)
-
-		for _, run := range renderer.Message.Runs {
-			switch {
-			case run.Text != "":
-				textBuilder.WriteString(run.Text)
-			case run.Emoji.IsCustomEmoji:
-				if images := run.Emoji.Image.Thumbnails; len(images) > 0 {
-					thumbnailsBuffer = append(thumbnailsBuffer, images[len(images)-1].Url)
-				}
-				for _, url := range thumbnailsBuffer {
-					textBuilder.WriteString(" ")
-					textBuilder.WriteString(url)
-					textBuilder.WriteString(" ")
-				}
-			default:
-				textBuilder.WriteString(run.Emoji.EmojiId)
-			}
-		}
-
-		chatMessages = append(chatMessages, types.YTChatMessage{
-			ID: renderer.ID,
-			Author: types.YTAuthor{
-				AuthorName:   renderer.AuthorName.SimpleText,
-				AuthorID:     renderer.AuthorExternalChannelID,
-				AuthorImages: renderer.AuthorPhoto.Thumbnails,
-			},
-			Timestamp: parseMicroSeconds(renderer.TimestampUsec),
-			Message:   textBuilder.String(),
-		})
+		return nil, s.parseError("sendMessage", errors.New("no known continuation data type found"))
 	}
 
-	return chatMessages, nil
+	return s.parseActions(chatMsgResp.ContinuationContents.LiveChatContinuation.Actions), nil
 }
 
 // parseMicroSeconds parses a microsecond timestamp string to time.Time.
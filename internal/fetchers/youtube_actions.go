@@ -0,0 +1,654 @@
+package fetchers
+
+import (
+	"github.com/xorvus/scrap-chat/internal/utils"
+	"github.com/xorvus/scrap-chat/types"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	regDuration = regexp.MustCompile(`(?i)(\d+)\s*(months?|years?)`)
+	regNumber   = regexp.MustCompile(`\d+`)
+	regMention  = regexp.MustCompile(`(?:^|\s)([@#][\p{L}\p{N}_.\-]+)`)
+	regCount    = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*([KkMm]?)`)
+)
+
+const maxRecentAuthors = 5000
+
+// recentAuthors remembers who wrote the latest messages, deletions only carry
+// the target message ID.
+type recentAuthors struct {
+	mu    sync.Mutex
+	ids   map[string]string
+	order []string
+}
+
+func (r *recentAuthors) add(messageID, authorID string) {
+	if messageID == "" || authorID == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ids == nil {
+		r.ids = make(map[string]string)
+	}
+	if _, ok := r.ids[messageID]; ok {
+		return
+	}
+	if len(r.order) >= maxRecentAuthors {
+		delete(r.ids, r.order[0])
+		r.order = r.order[1:]
+	}
+	r.ids[messageID] = authorID
+	r.order = append(r.order, messageID)
+}
+
+func (r *recentAuthors) get(messageID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ids[messageID]
+}
+
+// activeBanners keeps pinned banners until they are removed so the unpin event
+// can report what was pinned.
+type activeBanners struct {
+	mu      sync.Mutex
+	banners map[string]*types.BannerDetails
+}
+
+func (b *activeBanners) pin(details *types.BannerDetails) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.banners == nil {
+		b.banners = make(map[string]*types.BannerDetails)
+	}
+	b.banners[details.ActionID] = details
+}
+
+func (b *activeBanners) unpin(actionID string) *types.BannerDetails {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	details, ok := b.banners[actionID]
+	if !ok {
+		return &types.BannerDetails{ActionID: actionID}
+	}
+	delete(b.banners, actionID)
+	return details
+}
+
+// activePolls maps action panels to the last known state of their poll, the
+// close action only references the panel. Panels without a poll map to nil.
+type activePolls struct {
+	mu     sync.Mutex
+	panels map[string]*types.PollDetails
+	// orphans are polls already open when the capture started, only their
+	// updates were seen so their panel is unknown.
+	orphans map[string]*types.PollDetails
+}
+
+func (p *activePolls) show(panelID string, details *types.PollDetails) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.panels == nil {
+		p.panels = make(map[string]*types.PollDetails)
+	}
+	p.panels[panelID] = details
+	if details != nil {
+		delete(p.orphans, details.ID)
+	}
+}
+
+func (p *activePolls) update(details *types.PollDetails) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for panelID, poll := range p.panels {
+		if poll != nil && poll.ID == details.ID {
+			details.PanelID = panelID
+			p.panels[panelID] = details
+			return
+		}
+	}
+	if p.orphans == nil {
+		p.orphans = make(map[string]*types.PollDetails)
+	}
+	p.orphans[details.ID] = details
+}
+
+// close returns the poll of panelID, nil when the panel held something else.
+// An unknown panel belongs to a poll opened before the capture, it gets the
+// only orphan poll if there is one and otherwise just the panel ID.
+func (p *activePolls) close(panelID string) *types.PollDetails {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if details, ok := p.panels[panelID]; ok {
+		delete(p.panels, panelID)
+		return details
+	}
+	if len(p.orphans) == 1 {
+		for pollID, details := range p.orphans {
+			delete(p.orphans, pollID)
+			closed := *details
+			closed.PanelID = panelID
+			return &closed
+		}
+	}
+	return &types.PollDetails{PanelID: panelID}
+}
+
+func (s *liveSession) parseActions(actions []types.YTActions) []types.YTChatMessage {
+	chatMessages := make([]types.YTChatMessage, 0, len(actions))
+
+	for _, action := range actions {
+		switch {
+		case action.MarkChatItemAsDeletedAction != nil:
+			deleted := action.MarkChatItemAsDeletedAction
+			chatMessages = append(chatMessages, newModerationMessage(types.LiveChatDeleted, &types.ModerationDetails{
+				TargetID:       deleted.TargetItemID,
+				TargetAuthorID: s.authors.get(deleted.TargetItemID),
+				StateMessage:   textOf(deleted.DeletedStateMessage),
+			}))
+
+		case action.MarkChatItemsByAuthorAsDeletedAction != nil:
+			deleted := action.MarkChatItemsByAuthorAsDeletedAction
+			chatMessages = append(chatMessages, newModerationMessage(types.LiveChatAuthorDeleted, &types.ModerationDetails{
+				TargetAuthorID: deleted.ExternalChannelID,
+				StateMessage:   textOf(deleted.DeletedStateMessage),
+			}))
+
+		case action.ReplaceChatItemAction != nil:
+			replaced := action.ReplaceChatItemAction
+			details := &types.ModerationDetails{
+				TargetID:       replaced.TargetItemID,
+				TargetAuthorID: s.authors.get(replaced.TargetItemID),
+			}
+			if msg, ok := parseChatItem(replaced.ReplacementItem); ok {
+				details.Replacement = toLiveChatMessage(msg)
+				details.StateMessage = msg.Message
+			}
+			chatMessages = append(chatMessages, newModerationMessage(types.LiveChatReplaced, details))
+
+		case action.AddBannerToLiveChatCommand != nil:
+			banner := action.AddBannerToLiveChatCommand.BannerRenderer.LiveChatBannerRenderer
+			details := &types.BannerDetails{
+				ActionID:   banner.ActionID,
+				BannerType: banner.BannerType,
+				Header:     textOf(banner.Header.LiveChatBannerHeaderRenderer.Text),
+				PinnedAt:   time.Now().Unix(),
+			}
+			msg := types.YTChatMessage{}
+			if content, ok := parseChatItem(banner.Contents); ok {
+				msg = content
+				details.Message = toLiveChatMessage(content)
+			}
+			s.banners.pin(details)
+			msg.ID = details.ActionID
+			msg.Type = types.LiveChatPinned
+			// Q&A highlights reuse the banner with the question as contents.
+			if strings.Contains(details.BannerType, "QNA") {
+				msg.Type = types.LiveChatQuestion
+			}
+			msg.Timestamp = time.Unix(details.PinnedAt, 0)
+			msg.Banner = details
+			chatMessages = append(chatMessages, msg)
+
+		case action.RemoveBannerForLiveChatCommand != nil:
+			pinned := s.banners.unpin(action.RemoveBannerForLiveChatCommand.TargetActionID)
+			details := *pinned
+			details.UnpinnedAt = time.Now().Unix()
+			msg := types.YTChatMessage{
+				ID:        details.ActionID,
+				Type:      types.LiveChatUnpinned,
+				Timestamp: time.Unix(details.UnpinnedAt, 0),
+				Banner:    &details,
+			}
+			if details.Message != nil {
+				msg.Message = details.Message.Message
+				msg.Author = types.YTAuthor{
+					AuthorID:   details.Message.Author.ID,
+					AuthorName: details.Message.Author.Name,
+				}
+				if thumbnail := details.Message.Author.Thumbnail; thumbnail != "" {
+					msg.Author.AuthorImages = []types.YTThumbnails{{URL: thumbnail}}
+				}
+			}
+			chatMessages = append(chatMessages, msg)
+
+		case action.ShowLiveChatActionPanelAction != nil:
+			panel := action.ShowLiveChatActionPanelAction.PanelToShow.LiveChatActionPanelRenderer
+			if panel.Contents.PollRenderer == nil {
+				s.polls.show(panel.ID, nil)
+				continue
+			}
+			details := newPollDetails(panel.Contents.PollRenderer)
+			details.PanelID = panel.ID
+			s.polls.show(panel.ID, details)
+			chatMessages = append(chatMessages, newPollMessage(types.LiveChatPollCreated, details))
+
+		case action.UpdateLiveChatPollAction != nil:
+			details := newPollDetails(&action.UpdateLiveChatPollAction.PollToUpdate.PollRenderer)
+			s.polls.update(details)
+			chatMessages = append(chatMessages, newPollMessage(types.LiveChatPollUpdated, details))
+
+		case action.CloseLiveChatActionPanelAction != nil:
+			details := s.polls.close(action.CloseLiveChatActionPanelAction.TargetPanelID)
+			if details == nil {
+				continue
+			}
+			chatMessages = append(chatMessages, newPollMessage(types.LiveChatPollClosed, details))
+
+		default:
+			if msg, ok := parseChatItem(action.AddChatItemAction.Item); ok {
+				s.authors.add(msg.ID, msg.Author.AuthorID)
+				chatMessages = append(chatMessages, msg)
+			}
+		}
+	}
+
+	return chatMessages
+}
+
+func parseChatItem(item types.YTChatItem) (types.YTChatMessage, bool) {
+	switch {
+	case item.LiveChatTextMessageRenderer != nil:
+		renderer := item.LiveChatTextMessageRenderer
+		if len(renderer.Message.Runs) == 0 {
+			return types.YTChatMessage{}, false
+		}
+		return newChatMessage(types.LiveChatText, renderer), true
+
+	case item.LiveChatPaidMessageRenderer != nil:
+		renderer := item.LiveChatPaidMessageRenderer
+		msg := newChatMessage(types.LiveChatSuperChat, renderer)
+		msg.Paid = newPaidDetails(renderer, types.PaidColors{
+			HeaderBackground: renderer.HeaderBackgroundColor,
+			HeaderText:       renderer.HeaderTextColor,
+			BodyBackground:   renderer.BodyBackgroundColor,
+			BodyText:         renderer.BodyTextColor,
+			AuthorName:       renderer.AuthorNameTextColor,
+		})
+		return msg, true
+
+	case item.LiveChatPaidStickerRenderer != nil:
+		renderer := item.LiveChatPaidStickerRenderer
+		msg := newChatMessage(types.LiveChatSuperSticker, renderer)
+		msg.Paid = newPaidDetails(renderer, types.PaidColors{
+			HeaderBackground: renderer.MoneyChipBackgroundColor,
+			HeaderText:       renderer.MoneyChipTextColor,
+			BodyBackground:   renderer.BackgroundColor,
+			AuthorName:       renderer.AuthorNameTextColor,
+		})
+		msg.Paid.Sticker = &types.Sticker{
+			Image: largestThumbnail(renderer.Sticker.Thumbnails),
+			Label: renderer.Sticker.Accessibility.AccessibilityData.Label,
+		}
+		return msg, true
+
+	case item.LiveChatMembershipItemRenderer != nil:
+		return newMembershipMessage(item.LiveChatMembershipItemRenderer), true
+
+	case item.LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer != nil:
+		return newGiftPurchaseMessage(item.LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer), true
+
+	case item.LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer != nil:
+		return newGiftRedemptionMessage(item.LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer), true
+	}
+
+	return types.YTChatMessage{}, false
+}
+
+// newModerationMessage builds the retraction event, the ID and author point at
+// the affected message so consumers can match it against what they stored.
+func newModerationMessage(msgType types.LiveChatMessageType, details *types.ModerationDetails) types.YTChatMessage {
+	return types.YTChatMessage{
+		ID:         details.TargetID,
+		Type:       msgType,
+		Author:     types.YTAuthor{AuthorID: details.TargetAuthorID},
+		Timestamp:  time.Now(),
+		Message:    details.StateMessage,
+		Moderation: details,
+	}
+}
+
+func newChatMessage(msgType types.LiveChatMessageType, renderer *types.YTChatItemRenderer) types.YTChatMessage {
+	return types.YTChatMessage{
+		ID:   renderer.ID,
+		Type: msgType,
+		Author: types.YTAuthor{
+			AuthorName:   renderer.AuthorName.SimpleText,
+			AuthorID:     renderer.AuthorExternalChannelID,
+			AuthorImages: renderer.AuthorPhoto.Thumbnails,
+			AuthorBadges: renderer.AuthorBadges,
+		},
+		Timestamp: parseMicroSeconds(renderer.TimestampUsec),
+		Message:   flattenRuns(renderer.Message.Runs),
+		Segments:  parseSegments(renderer.Message.Runs),
+	}
+}
+
+func newPaidDetails(renderer *types.YTChatItemRenderer, colors types.PaidColors) *types.PaidDetails {
+	amount := renderer.PurchaseAmountText.SimpleText
+	currency, micros := utils.ParseCurrency(amount)
+	return &types.PaidDetails{
+		AmountText:   amount,
+		Currency:     currency,
+		AmountMicros: micros,
+		Colors:       colors,
+	}
+}
+
+// newMembershipMessage handles both new members, whose header subtext reads
+// "Welcome to <tier>!", and milestones, which carry "Member for N months" in
+// the primary header and the tier name in the subtext.
+func newMembershipMessage(renderer *types.YTChatItemRenderer) types.YTChatMessage {
+	details := &types.MembershipDetails{}
+
+	var msg types.YTChatMessage
+	if primary := textOf(renderer.HeaderPrimaryText); primary != "" {
+		msg = newChatMessage(types.LiveChatMembershipMilestone, renderer)
+		details.HeaderText = primary
+		details.Months = parseMonths(primary)
+		details.TierName = textOf(renderer.HeaderSubtext)
+	} else {
+		msg = newChatMessage(types.LiveChatMembership, renderer)
+		details.HeaderText = textOf(renderer.HeaderSubtext)
+		if runs := renderer.HeaderSubtext.Runs; len(runs) == 3 {
+			details.TierName = runs[1].Text
+		}
+	}
+
+	msg.Membership = details
+	return msg
+}
+
+// newGiftPurchaseMessage reads the gifter from the sponsorships header, the
+// primary text reads "Gifted 5 <channel> memberships".
+func newGiftPurchaseMessage(renderer *types.YTChatItemRenderer) types.YTChatMessage {
+	msg := newChatMessage(types.LiveChatMembershipGift, renderer)
+
+	details := &types.MembershipDetails{}
+	if header := renderer.Header.LiveChatSponsorshipsHeaderRenderer; header != nil {
+		msg.Author.AuthorName = header.AuthorName.SimpleText
+		msg.Author.AuthorImages = header.AuthorPhoto.Thumbnails
+		msg.Author.AuthorBadges = header.AuthorBadges
+		details.HeaderText = textOf(header.PrimaryText)
+		details.GiftCount = firstNumber(details.HeaderText)
+	}
+
+	gifter := toAuthor(msg.Author)
+	details.Gifter = &gifter
+	msg.Message = details.HeaderText
+	msg.Membership = details
+	return msg
+}
+
+// newGiftRedemptionMessage is sent for the recipient, the gifter only appears
+// by name as the bold run of "received a gift membership by <gifter>".
+func newGiftRedemptionMessage(renderer *types.YTChatItemRenderer) types.YTChatMessage {
+	msg := newChatMessage(types.LiveChatMembershipGiftRedemption, renderer)
+
+	recipient := toAuthor(msg.Author)
+	details := &types.MembershipDetails{
+		HeaderText: msg.Message,
+		Recipient:  &recipient,
+	}
+	for _, run := range renderer.Message.Runs {
+		if run.Bold {
+			details.Gifter = &types.Author{Name: run.Text}
+		}
+	}
+
+	msg.Membership = details
+	return msg
+}
+
+// newPollDetails reads the total from the "• 12 votes" part of the header
+// metadata, YouTube only sends a ratio per choice.
+func newPollDetails(renderer *types.YTPollRenderer) *types.PollDetails {
+	header := renderer.Header.PollHeaderRenderer
+	details := &types.PollDetails{
+		ID:       renderer.LiveChatPollID,
+		PollType: header.LiveChatPollType,
+		Question: textOf(header.PollQuestion),
+		Choices:  make([]types.PollChoice, 0, len(renderer.Choices)),
+	}
+	for _, run := range header.MetadataText.Runs {
+		if strings.Contains(strings.ToLower(run.Text), "vote") {
+			details.TotalVotes = parseCount(run.Text)
+		}
+	}
+	for _, choice := range renderer.Choices {
+		details.Choices = append(details.Choices, types.PollChoice{
+			Text:       textOf(choice.Text),
+			Percentage: choice.VoteRatio * 100,
+			Votes:      int(math.Round(choice.VoteRatio * float64(details.TotalVotes))),
+			Selected:   choice.Selected,
+		})
+	}
+	return details
+}
+
+func newPollMessage(msgType types.LiveChatMessageType, details *types.PollDetails) types.YTChatMessage {
+	return types.YTChatMessage{
+		ID:        details.ID,
+		Type:      msgType,
+		Message:   details.Question,
+		Timestamp: time.Now(),
+		Poll:      details,
+	}
+}
+
+// applyBadges sets the role flags, member badges have a custom thumbnail
+// instead of an icon.
+func applyBadges(author *types.Author, badges []types.YTAuthorBadges) {
+	for _, badge := range badges {
+		renderer := badge.LiveChatAuthorBadgeRenderer
+		switch renderer.Icon.IconType {
+		case "OWNER":
+			author.IsUploader = true
+		case "MODERATOR":
+			author.IsModerator = true
+		case "VERIFIED", "CHECK_CIRCLE_THICK":
+			author.IsVerified = true
+		}
+
+		if len(renderer.CustomThumbnail.Thumbnails) > 0 {
+			label, _, _ := strings.Cut(renderer.Tooltip, " (")
+			author.IsMember = true
+			author.Member = &types.MemberBadge{
+				Label:  label,
+				Months: parseMonths(renderer.Tooltip),
+				Image:  largestThumbnail(renderer.CustomThumbnail.Thumbnails),
+			}
+		}
+	}
+}
+
+func textOf(text types.YTText) string {
+	if text.SimpleText != "" {
+		return text.SimpleText
+	}
+	return flattenRuns(text.Runs)
+}
+
+// parseMonths reads durations such as "Member for 6 months" or
+// "Member (1 year, 2 months)".
+func parseMonths(text string) int {
+	months := 0
+	for _, m := range regDuration.FindAllStringSubmatch(text, -1) {
+		n, _ := strconv.Atoi(m[1])
+		if strings.HasPrefix(strings.ToLower(m[2]), "year") {
+			n *= 12
+		}
+		months += n
+	}
+	return months
+}
+
+// parseCount reads abbreviated counts such as "1.2K votes".
+func parseCount(text string) int {
+	m := regCount.FindStringSubmatch(strings.ReplaceAll(text, ",", ""))
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.ParseFloat(m[1], 64)
+	switch strings.ToUpper(m[2]) {
+	case "K":
+		n *= 1e3
+	case "M":
+		n *= 1e6
+	}
+	return int(math.Round(n))
+}
+
+func firstNumber(text string) int {
+	n, _ := strconv.Atoi(regNumber.FindString(strings.ReplaceAll(text, ",", "")))
+	return n
+}
+
+// flattenRuns joins message runs into plain text, custom emoji are written as
+// their image URL.
+func flattenRuns(runs []types.YTRuns) string {
+	var textBuilder strings.Builder
+	const avgMessageSize = 128
+	thumbnailsBuffer := make([]string, 0, 2)
+	textBuilder.Grow(avgMessageSize)
+
+	for _, run := range runs {
+		switch {
+		case run.Text != "":
+			textBuilder.WriteString(run.Text)
+		case run.Emoji.IsCustomEmoji:
+			if images := run.Emoji.Image.Thumbnails; len(images) > 0 {
+				thumbnailsBuffer = append(thumbnailsBuffer, images[len(images)-1].Url)
+			}
+			for _, url := range thumbnailsBuffer {
+				textBuilder.WriteString(" ")
+				textBuilder.WriteString(url)
+				textBuilder.WriteString(" ")
+			}
+		default:
+			textBuilder.WriteString(run.Emoji.EmojiId)
+		}
+	}
+
+	return textBuilder.String()
+}
+
+// parseSegments keeps the structure of the runs that flattenRuns loses.
+func parseSegments(runs []types.YTRuns) []types.MessageSegment {
+	if len(runs) == 0 {
+		return nil
+	}
+	segments := make([]types.MessageSegment, 0, len(runs))
+
+	for _, run := range runs {
+		switch {
+		case run.NavigationEndpoint != nil:
+			segments = append(segments, linkSegment(run))
+		case run.Text != "":
+			segments = append(segments, splitText(run.Text)...)
+		case run.Emoji.IsCustomEmoji:
+			segment := types.MessageSegment{
+				Type:    types.SegmentCustomEmoji,
+				EmojiID: run.Emoji.EmojiId,
+			}
+			if len(run.Emoji.Shortcuts) > 0 {
+				segment.Shortcut = run.Emoji.Shortcuts[0]
+				segment.Text = segment.Shortcut
+			}
+			if images := run.Emoji.Image.Thumbnails; len(images) > 0 {
+				segment.Image = images[len(images)-1].Url
+			}
+			segments = append(segments, segment)
+		case run.Emoji.EmojiId != "":
+			segment := types.MessageSegment{
+				Type:    types.SegmentEmoji,
+				Text:    run.Emoji.EmojiId,
+				EmojiID: run.Emoji.EmojiId,
+				Unicode: run.Emoji.EmojiId,
+			}
+			if len(run.Emoji.Shortcuts) > 0 {
+				segment.Shortcut = run.Emoji.Shortcuts[0]
+			}
+			if images := run.Emoji.Image.Thumbnails; len(images) > 0 {
+				segment.Image = images[len(images)-1].Url
+			}
+			segments = append(segments, segment)
+		}
+	}
+
+	return segments
+}
+
+func linkSegment(run types.YTRuns) types.MessageSegment {
+	target := run.NavigationEndpoint.URLEndpoint.URL
+	if target == "" {
+		target = run.NavigationEndpoint.CommandMetadata.WebCommandMetadata.URL
+	}
+	if strings.HasPrefix(target, "/") {
+		target = "https://www.youtube.com" + target
+	}
+
+	segment := types.MessageSegment{Type: types.SegmentLink, Text: run.Text, URL: unwrapRedirect(target)}
+	switch {
+	case strings.HasPrefix(run.Text, "#"):
+		segment.Type = types.SegmentHashtag
+	case strings.HasPrefix(run.Text, "@"):
+		segment.Type = types.SegmentMention
+	}
+	return segment
+}
+
+// unwrapRedirect returns the q parameter of youtube.com/redirect links.
+func unwrapRedirect(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || !strings.HasSuffix(u.Host, "youtube.com") || u.Path != "/redirect" {
+		return target
+	}
+	if q := u.Query().Get("q"); q != "" {
+		return q
+	}
+	return target
+}
+
+// splitText cuts plain text around @mentions and #hashtags, live chat sends
+// them as ordinary text runs.
+func splitText(text string) []types.MessageSegment {
+	var segments []types.MessageSegment
+	last := 0
+	for _, m := range regMention.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[2], m[3]
+		if start > last {
+			segments = append(segments, types.MessageSegment{Type: types.SegmentText, Text: text[last:start]})
+		}
+		segmentType := types.SegmentMention
+		if text[start] == '#' {
+			segmentType = types.SegmentHashtag
+		}
+		segments = append(segments, types.MessageSegment{Type: segmentType, Text: text[start:end]})
+		last = end
+	}
+	if last < len(text) {
+		segments = append(segments, types.MessageSegment{Type: types.SegmentText, Text: text[last:]})
+	}
+	return segments
+}
+
+// largestThumbnail returns the last (biggest) thumbnail URL, YouTube serves
+// some of them protocol relative.
+func largestThumbnail(thumbnails []types.YTThumbnails) string {
+	if len(thumbnails) == 0 {
+		return ""
+	}
+	url := thumbnails[len(thumbnails)-1].URL
+	if strings.HasPrefix(url, "//") {
+		url = "https:" + url
+	}
+	return url
+}
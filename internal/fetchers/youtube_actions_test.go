@@ -0,0 +1,474 @@
+package fetchers
+
+import (
+	"encoding/json"
+	"github.com/xorvus/scrap-chat/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseResponse decodes a get_live_chat response made of actions and parses
+// it the way a capture does.
+func parseResponse(t *testing.T, s *liveSession, actions ...string) []types.YTChatMessage {
+	t.Helper()
+	body := `{"continuationContents":{"liveChatContinuation":{"actions":[` + strings.Join(actions, ",") + `]}}}`
+	var resp types.YTChatMessagesResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return s.parseActions(resp.ContinuationContents.LiveChatContinuation.Actions)
+}
+
+func TestParseActionsPaid(t *testing.T) {
+	msgs := parseResponse(t, &liveSession{},
+		`{"addChatItemAction":{"item":{"liveChatPaidMessageRenderer":{
+			"id":"sc1","timestampUsec":"1700000000123456",
+			"authorName":{"simpleText":"alice"},
+			"authorPhoto":{"thumbnails":[{"url":"https://yt3.ggpht.com/alice=s32","width":32,"height":32}]},
+			"authorExternalChannelId":"UCalice",
+			"purchaseAmountText":{"simpleText":"$5.00"},
+			"message":{"runs":[{"text":"great stream"}]},
+			"headerBackgroundColor":4278239141,"headerTextColor":4278190080,
+			"bodyBackgroundColor":4280150454,"bodyTextColor":4278190080,
+			"authorNameTextColor":2315255808}}}}`,
+		`{"addChatItemAction":{"item":{"liveChatPaidStickerRenderer":{
+			"id":"st1","timestampUsec":"1700000001000000",
+			"authorName":{"simpleText":"bob"},
+			"authorExternalChannelId":"UCbob",
+			"purchaseAmountText":{"simpleText":"€2,00"},
+			"sticker":{"thumbnails":[{"url":"//lh3.googleusercontent.com/s=s40"},{"url":"//lh3.googleusercontent.com/s=s80"}],
+				"accessibility":{"accessibilityData":{"label":"A dancing cat"}}},
+			"moneyChipBackgroundColor":4280191205,"moneyChipTextColor":4294967295,
+			"backgroundColor":4279592384,"authorNameTextColor":3019898879}}}}`,
+	)
+	if len(msgs) != 2 {
+		t.Fatalf("parsed %d messages, want 2", len(msgs))
+	}
+
+	want := &types.LiveChatMessage{
+		ID:       "sc1",
+		Type:     types.LiveChatSuperChat,
+		Message:  "great stream",
+		Segments: []types.MessageSegment{{Type: types.SegmentText, Text: "great stream"}},
+		Author: types.Author{
+			ID:        "UCalice",
+			Name:      "alice",
+			Thumbnail: "https://yt3.ggpht.com/alice=s32",
+			URL:       "https://youtube.com/channel/UCalice",
+		},
+		Timestamp: 1700000000,
+		Paid: &types.PaidDetails{
+			AmountText:   "$5.00",
+			Currency:     "USD",
+			AmountMicros: 5_000_000,
+			Colors: types.PaidColors{
+				HeaderBackground: 4278239141,
+				HeaderText:       4278190080,
+				BodyBackground:   4280150454,
+				BodyText:         4278190080,
+				AuthorName:       2315255808,
+			},
+		},
+	}
+	if got := toLiveChatMessage(msgs[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("super chat = %+v\nwant %+v", got, want)
+	}
+	if got := msgs[0].Timestamp.UnixMicro(); got != 1700000000123000 {
+		t.Errorf("super chat timestamp = %d µs, want millisecond precision", got)
+	}
+
+	sticker := toLiveChatMessage(msgs[1])
+	if sticker.Type != types.LiveChatSuperSticker || sticker.Message != "" || sticker.Segments != nil {
+		t.Errorf("super sticker = %+v", sticker)
+	}
+	wantPaid := &types.PaidDetails{
+		AmountText:   "€2,00",
+		Currency:     "EUR",
+		AmountMicros: 2_000_000,
+		Colors: types.PaidColors{
+			HeaderBackground: 4280191205,
+			HeaderText:       4294967295,
+			BodyBackground:   4279592384,
+			AuthorName:       3019898879,
+		},
+		Sticker: &types.Sticker{Image: "https://lh3.googleusercontent.com/s=s80", Label: "A dancing cat"},
+	}
+	if !reflect.DeepEqual(sticker.Paid, wantPaid) {
+		t.Errorf("super sticker paid = %+v\nwant %+v", sticker.Paid, wantPaid)
+	}
+}
+
+func TestParseActionsMembership(t *testing.T) {
+	msgs := parseResponse(t, &liveSession{},
+		`{"addChatItemAction":{"item":{"liveChatMembershipItemRenderer":{
+			"id":"m1","timestampUsec":"1700000000000000",
+			"authorName":{"simpleText":"alice"},"authorExternalChannelId":"UCalice",
+			"headerSubtext":{"runs":[{"text":"Welcome to "},{"text":"Gold"},{"text":"!"}]}}}}}`,
+		`{"addChatItemAction":{"item":{"liveChatMembershipItemRenderer":{
+			"id":"m2","timestampUsec":"1700000001000000",
+			"authorName":{"simpleText":"bob"},"authorExternalChannelId":"UCbob",
+			"headerPrimaryText":{"runs":[{"text":"Member for "},{"text":"14"},{"text":" months"}]},
+			"headerSubtext":{"simpleText":"Gold"},
+			"message":{"runs":[{"text":"still here"}]}}}}}`,
+		`{"addChatItemAction":{"item":{"liveChatSponsorshipsGiftPurchaseAnnouncementRenderer":{
+			"id":"g1","timestampUsec":"1700000002000000","authorExternalChannelId":"UCcarol",
+			"header":{"liveChatSponsorshipsHeaderRenderer":{
+				"authorName":{"simpleText":"carol"},
+				"authorPhoto":{"thumbnails":[{"url":"https://yt3.ggpht.com/carol"}]},
+				"primaryText":{"runs":[{"text":"Gifted "},{"text":"5","bold":true},{"text":" "},{"text":"Fake channel","bold":true},{"text":" memberships"}]}}}}}}}`,
+		`{"addChatItemAction":{"item":{"liveChatSponsorshipsGiftRedemptionAnnouncementRenderer":{
+			"id":"r1","timestampUsec":"1700000003000000",
+			"authorName":{"simpleText":"dave"},"authorExternalChannelId":"UCdave",
+			"message":{"runs":[{"text":"received a gift membership by "},{"text":"carol","bold":true}]}}}}}`,
+	)
+	if len(msgs) != 4 {
+		t.Fatalf("parsed %d messages, want 4", len(msgs))
+	}
+
+	join := toLiveChatMessage(msgs[0])
+	if join.Type != types.LiveChatMembership || join.Author.Name != "alice" {
+		t.Errorf("join = %+v", join)
+	}
+	if want := (&types.MembershipDetails{TierName: "Gold", HeaderText: "Welcome to Gold!"}); !reflect.DeepEqual(join.Membership, want) {
+		t.Errorf("join membership = %+v, want %+v", join.Membership, want)
+	}
+
+	milestone := toLiveChatMessage(msgs[1])
+	if milestone.Type != types.LiveChatMembershipMilestone || milestone.Message != "still here" {
+		t.Errorf("milestone = %+v", milestone)
+	}
+	if want := (&types.MembershipDetails{TierName: "Gold", Months: 14, HeaderText: "Member for 14 months"}); !reflect.DeepEqual(milestone.Membership, want) {
+		t.Errorf("milestone membership = %+v, want %+v", milestone.Membership, want)
+	}
+
+	gift := toLiveChatMessage(msgs[2])
+	carol := types.Author{
+		ID:        "UCcarol",
+		Name:      "carol",
+		Thumbnail: "https://yt3.ggpht.com/carol",
+		URL:       "https://youtube.com/channel/UCcarol",
+	}
+	if gift.Type != types.LiveChatMembershipGift || gift.Message != "Gifted 5 Fake channel memberships" || !reflect.DeepEqual(gift.Author, carol) {
+		t.Errorf("gift purchase = %+v", gift)
+	}
+	wantGift := &types.MembershipDetails{GiftCount: 5, HeaderText: "Gifted 5 Fake channel memberships", Gifter: &carol}
+	if !reflect.DeepEqual(gift.Membership, wantGift) {
+		t.Errorf("gift purchase membership = %+v, want %+v", gift.Membership, wantGift)
+	}
+
+	redemption := toLiveChatMessage(msgs[3])
+	if redemption.Type != types.LiveChatMembershipGiftRedemption || redemption.Author.Name != "dave" {
+		t.Errorf("gift redemption = %+v", redemption)
+	}
+	m := redemption.Membership
+	if m == nil || m.HeaderText != "received a gift membership by carol" ||
+		m.Gifter == nil || m.Gifter.Name != "carol" || m.Recipient == nil || m.Recipient.ID != "UCdave" {
+		t.Errorf("gift redemption membership = %+v", m)
+	}
+}
+
+func TestParseMonths(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"Member for 6 months", 6},
+		{"Member for 1 month", 1},
+		{"Member (1 year, 2 months)", 14},
+		{"Member (2 years)", 24},
+		{"New member", 0},
+	}
+	for _, tt := range tests {
+		if got := parseMonths(tt.text); got != tt.want {
+			t.Errorf("parseMonths(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseActionsModeration(t *testing.T) {
+	s := &liveSession{}
+	parseResponse(t, s, `{"addChatItemAction":{"item":{"liveChatTextMessageRenderer":{
+		"id":"t1","timestampUsec":"1700000000000000",
+		"authorName":{"simpleText":"alice"},"authorExternalChannelId":"UCalice",
+		"message":{"runs":[{"text":"spam"}]}}}}}`)
+
+	msgs := parseResponse(t, s,
+		`{"markChatItemAsDeletedAction":{"targetItemId":"t1","deletedStateMessage":{"runs":[{"text":"[message retracted]"}]}}}`,
+		`{"markChatItemsByAuthorAsDeletedAction":{"externalChannelId":"UCbob","deletedStateMessage":{"runs":[{"text":"[message deleted]"}]}}}`,
+		`{"replaceChatItemAction":{"targetItemId":"t1","replacementItem":{"liveChatTextMessageRenderer":{
+			"id":"t1","timestampUsec":"1700000000000000",
+			"authorName":{"simpleText":"alice"},"authorExternalChannelId":"UCalice",
+			"message":{"runs":[{"text":"sorry"}]}}}}}`,
+	)
+	if len(msgs) != 3 {
+		t.Fatalf("parsed %d messages, want 3", len(msgs))
+	}
+
+	deleted := toLiveChatMessage(msgs[0])
+	wantDeleted := &types.ModerationDetails{TargetID: "t1", TargetAuthorID: "UCalice", StateMessage: "[message retracted]"}
+	if deleted.Type != types.LiveChatDeleted || deleted.ID != "t1" || deleted.Author.ID != "UCalice" || !reflect.DeepEqual(deleted.Moderation, wantDeleted) {
+		t.Errorf("deleted = %+v, moderation %+v", deleted, deleted.Moderation)
+	}
+
+	byAuthor := toLiveChatMessage(msgs[1])
+	wantByAuthor := &types.ModerationDetails{TargetAuthorID: "UCbob", StateMessage: "[message deleted]"}
+	if byAuthor.Type != types.LiveChatAuthorDeleted || byAuthor.ID != "" || byAuthor.Author.ID != "UCbob" || !reflect.DeepEqual(byAuthor.Moderation, wantByAuthor) {
+		t.Errorf("author deleted = %+v, moderation %+v", byAuthor, byAuthor.Moderation)
+	}
+
+	replaced := toLiveChatMessage(msgs[2])
+	m := replaced.Moderation
+	if replaced.Type != types.LiveChatReplaced || replaced.Message != "sorry" || m == nil ||
+		m.TargetID != "t1" || m.TargetAuthorID != "UCalice" || m.Replacement == nil ||
+		m.Replacement.Type != types.LiveChatText || m.Replacement.Message != "sorry" || m.Replacement.Author.Name != "alice" {
+		t.Errorf("replaced = %+v, moderation %+v", replaced, m)
+	}
+}
+
+func TestParseActionsBanners(t *testing.T) {
+	s := &liveSession{}
+	msgs := parseResponse(t, s,
+		`{"addBannerToLiveChatCommand":{"bannerRenderer":{"liveChatBannerRenderer":{
+			"header":{"liveChatBannerHeaderRenderer":{"text":{"runs":[{"text":"Pinned by "},{"text":"host"}]}}},
+			"contents":{"liveChatTextMessageRenderer":{
+				"id":"t1","timestampUsec":"1700000000000000",
+				"authorName":{"simpleText":"alice"},"authorExternalChannelId":"UCalice",
+				"authorPhoto":{"thumbnails":[{"url":"https://yt3.ggpht.com/alice"}]},
+				"message":{"runs":[{"text":"read the rules"}]}}},
+			"actionId":"b1","targetId":"live-chat-banner","bannerType":"LIVE_CHAT_BANNER_TYPE_PINNED_MESSAGE"}}}}`,
+		`{"addBannerToLiveChatCommand":{"bannerRenderer":{"liveChatBannerRenderer":{
+			"header":{"liveChatBannerHeaderRenderer":{"text":{"simpleText":"Question"}}},
+			"contents":{"liveChatTextMessageRenderer":{
+				"id":"t2","timestampUsec":"1700000001000000",
+				"authorName":{"simpleText":"bob"},"authorExternalChannelId":"UCbob",
+				"message":{"runs":[{"text":"when is the next stream?"}]}}},
+			"actionId":"b2","targetId":"live-chat-banner","bannerType":"LIVE_CHAT_BANNER_TYPE_QNA"}}}}`,
+		`{"removeBannerForLiveChatCommand":{"targetActionId":"b1"}}`,
+		`{"removeBannerForLiveChatCommand":{"targetActionId":"unknown"}}`,
+	)
+	if len(msgs) != 4 {
+		t.Fatalf("parsed %d messages, want 4", len(msgs))
+	}
+
+	pinned := toLiveChatMessage(msgs[0])
+	b := pinned.Banner
+	if pinned.Type != types.LiveChatPinned || pinned.ID != "b1" || pinned.Message != "read the rules" || pinned.Author.Name != "alice" {
+		t.Errorf("pinned = %+v", pinned)
+	}
+	if b == nil || b.ActionID != "b1" || b.BannerType != "LIVE_CHAT_BANNER_TYPE_PINNED_MESSAGE" || b.Header != "Pinned by host" ||
+		b.PinnedAt == 0 || b.Message == nil || b.Message.ID != "t1" || b.Message.Timestamp != 1700000000 {
+		t.Errorf("pinned banner = %+v", b)
+	}
+
+	question := toLiveChatMessage(msgs[1])
+	if question.Type != types.LiveChatQuestion || question.ID != "b2" || question.Message != "when is the next stream?" || question.Banner.Header != "Question" {
+		t.Errorf("question = %+v", question)
+	}
+
+	unpinned := toLiveChatMessage(msgs[2])
+	b = unpinned.Banner
+	if unpinned.Type != types.LiveChatUnpinned || unpinned.ID != "b1" || unpinned.Message != "read the rules" ||
+		unpinned.Author.Name != "alice" || unpinned.Author.Thumbnail != "https://yt3.ggpht.com/alice" {
+		t.Errorf("unpinned = %+v", unpinned)
+	}
+	if b == nil || b.Header != "Pinned by host" || b.Message == nil || b.UnpinnedAt == 0 {
+		t.Errorf("unpinned banner = %+v", b)
+	}
+	if msgs[0].Banner.UnpinnedAt != 0 {
+		t.Error("unpinning changed the pinned event")
+	}
+
+	unknown := toLiveChatMessage(msgs[3])
+	if unknown.Type != types.LiveChatUnpinned || unknown.Banner.ActionID != "unknown" || unknown.Banner.Message != nil {
+		t.Errorf("unknown unpin = %+v", unknown)
+	}
+}
+
+func pollRenderer(votes string, yes float64) string {
+	return `{"liveChatPollId":"poll1",
+		"choices":[
+			{"text":{"runs":[{"text":"Yes"}]},"voteRatio":` + strconv.FormatFloat(yes, 'f', -1, 64) + `},
+			{"text":{"runs":[{"text":"No"}]},"selected":true,"voteRatio":` + strconv.FormatFloat(1-yes, 'f', -1, 64) + `}],
+		"header":{"pollHeaderRenderer":{
+			"pollQuestion":{"runs":[{"text":"More streams?"}]},
+			"metadataText":{"runs":[{"text":"host"},{"text":" • "},{"text":"just now"},{"text":" • "},{"text":"` + votes + `"}]},
+			"liveChatPollType":"LIVE_CHAT_POLL_TYPE_CREATOR"}}}`
+}
+
+func TestParseActionsPolls(t *testing.T) {
+	s := &liveSession{}
+	msgs := parseResponse(t, s,
+		`{"showLiveChatActionPanelAction":{"panelToShow":{"liveChatActionPanelRenderer":{"id":"panel1","contents":{"pollRenderer":`+pollRenderer("0 votes", 0)+`}}}}}`,
+		`{"updateLiveChatPollAction":{"pollToUpdate":{"pollRenderer":`+pollRenderer("1.2K votes", 0.75)+`}}}`,
+		`{"closeLiveChatActionPanelAction":{"targetPanelId":"panel1"}}`,
+	)
+	if len(msgs) != 3 {
+		t.Fatalf("parsed %d messages, want 3", len(msgs))
+	}
+
+	wantTypes := []types.LiveChatMessageType{types.LiveChatPollCreated, types.LiveChatPollUpdated, types.LiveChatPollClosed}
+	for i, msg := range msgs {
+		if msg.Type != wantTypes[i] || msg.ID != "poll1" || msg.Message != "More streams?" || msg.Poll == nil || msg.Poll.PanelID != "panel1" {
+			t.Errorf("event %d = %+v", i, msg)
+		}
+	}
+
+	want := &types.PollDetails{
+		ID:       "poll1",
+		PanelID:  "panel1",
+		PollType: "LIVE_CHAT_POLL_TYPE_CREATOR",
+		Question: "More streams?",
+		Choices: []types.PollChoice{
+			{Text: "Yes", Percentage: 75, Votes: 900},
+			{Text: "No", Percentage: 25, Votes: 300, Selected: true},
+		},
+		TotalVotes: 1200,
+	}
+	if got := toLiveChatMessage(msgs[2]).Poll; !reflect.DeepEqual(got, want) {
+		t.Errorf("closed poll = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseActionsPollOpenBeforeCapture(t *testing.T) {
+	s := &liveSession{}
+	msgs := parseResponse(t, s,
+		`{"showLiveChatActionPanelAction":{"panelToShow":{"liveChatActionPanelRenderer":{"id":"other","contents":{}}}}}`,
+		`{"updateLiveChatPollAction":{"pollToUpdate":{"pollRenderer":`+pollRenderer("10 votes", 0.5)+`}}}`,
+		`{"closeLiveChatActionPanelAction":{"targetPanelId":"other"}}`,
+		`{"closeLiveChatActionPanelAction":{"targetPanelId":"panel1"}}`,
+		`{"closeLiveChatActionPanelAction":{"targetPanelId":"panel2"}}`,
+	)
+	if len(msgs) != 3 {
+		t.Fatalf("parsed %d messages, want 3", len(msgs))
+	}
+
+	closed := msgs[1]
+	if closed.Type != types.LiveChatPollClosed || closed.ID != "poll1" || closed.Poll.PanelID != "panel1" || closed.Poll.TotalVotes != 10 {
+		t.Errorf("closed poll = %+v", closed.Poll)
+	}
+	if msgs[0].Poll.PanelID != "" {
+		t.Error("closing the poll changed the update event")
+	}
+
+	unknown := msgs[2]
+	if unknown.Type != types.LiveChatPollClosed || !reflect.DeepEqual(unknown.Poll, &types.PollDetails{PanelID: "panel2"}) {
+		t.Errorf("unknown panel close = %+v", unknown.Poll)
+	}
+}
+
+func TestParseCount(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"42 votes", 42},
+		{"1,234 votes", 1234},
+		{"1.2K votes", 1200},
+		{"3.4M", 3_400_000},
+		{"12k", 12_000},
+		{"no votes", 0},
+	}
+	for _, tt := range tests {
+		if got := parseCount(tt.text); got != tt.want {
+			t.Errorf("parseCount(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseActionsBadges(t *testing.T) {
+	badge := func(icon, tooltip string, thumbnails ...string) string {
+		custom := ""
+		for i, url := range thumbnails {
+			if i > 0 {
+				custom += ","
+			}
+			custom += `{"url":"` + url + `"}`
+		}
+		return `{"liveChatAuthorBadgeRenderer":{"icon":{"iconType":"` + icon + `"},"customThumbnail":{"thumbnails":[` + custom + `]},"tooltip":"` + tooltip + `"}}`
+	}
+	message := func(id string, badges ...string) string {
+		return `{"addChatItemAction":{"item":{"liveChatTextMessageRenderer":{
+			"id":"` + id + `","timestampUsec":"1700000000000000",
+			"authorName":{"simpleText":"` + id + `"},"authorExternalChannelId":"UC` + id + `",
+			"authorBadges":[` + strings.Join(badges, ",") + `],
+			"message":{"runs":[{"text":"hi"}]}}}}}`
+	}
+
+	msgs := parseResponse(t, &liveSession{},
+		message("owner", badge("OWNER", "Owner"), badge("VERIFIED", "Verified")),
+		message("mod", badge("MODERATOR", "Moderator"), badge("", "Member (1 year, 2 months)", "https://yt3.ggpht.com/b=s16", "https://yt3.ggpht.com/b=s32")),
+		message("plain"),
+	)
+	if len(msgs) != 3 {
+		t.Fatalf("parsed %d messages, want 3", len(msgs))
+	}
+
+	owner := toLiveChatMessage(msgs[0]).Author
+	if !owner.IsUploader || !owner.IsVerified || owner.IsModerator || owner.IsMember || owner.Member != nil {
+		t.Errorf("owner = %+v", owner)
+	}
+
+	mod := toLiveChatMessage(msgs[1]).Author
+	wantBadge := &types.MemberBadge{Label: "Member", Months: 14, Image: "https://yt3.ggpht.com/b=s32"}
+	if !mod.IsModerator || mod.IsUploader || mod.IsVerified || !mod.IsMember || !reflect.DeepEqual(mod.Member, wantBadge) {
+		t.Errorf("moderator = %+v, member badge %+v", mod, mod.Member)
+	}
+
+	plain := toLiveChatMessage(msgs[2]).Author
+	if plain.IsUploader || plain.IsVerified || plain.IsModerator || plain.IsMember || plain.Member != nil {
+		t.Errorf("author without badges = %+v", plain)
+	}
+}
+
+func TestUnwrapRedirect(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"https://www.youtube.com/redirect?event=live_chat&q=https%3A%2F%2Fexample.com%2Fa%3Fb%3D1", "https://example.com/a?b=1"},
+		{"https://youtube.com/redirect?q=https://example.com", "https://example.com"},
+		{"https://www.youtube.com/redirect?event=live_chat", "https://www.youtube.com/redirect?event=live_chat"},
+		{"https://www.youtube.com/watch?v=abc", "https://www.youtube.com/watch?v=abc"},
+		{"https://example.com/redirect?q=https://evil.test", "https://example.com/redirect?q=https://evil.test"},
+	}
+	for _, tt := range tests {
+		if got := unwrapRedirect(tt.target); got != tt.want {
+			t.Errorf("unwrapRedirect(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestParseSegments(t *testing.T) {
+	var runs []types.YTRuns
+	err := json.Unmarshal([]byte(`[
+		{"text": "hi @alice and #go "},
+		{"emoji": {"emojiId": "😀", "shortcuts": [":grinning:"], "image": {"thumbnails": [{"url": "small"}, {"url": "big"}]}}},
+		{"emoji": {"emojiId": "UC/abc", "shortcuts": [":yt:"], "isCustomEmoji": true, "image": {"thumbnails": [{"url": "custom"}]}}},
+		{"text": "docs", "navigationEndpoint": {"urlEndpoint": {"url": "https://www.youtube.com/redirect?q=https%3A%2F%2Fgo.dev"}}},
+		{"text": "#live", "navigationEndpoint": {"commandMetadata": {"webCommandMetadata": {"url": "/hashtag/live"}}}}
+	]`), &runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []types.MessageSegment{
+		{Type: types.SegmentText, Text: "hi "},
+		{Type: types.SegmentMention, Text: "@alice"},
+		{Type: types.SegmentText, Text: " and "},
+		{Type: types.SegmentHashtag, Text: "#go"},
+		{Type: types.SegmentText, Text: " "},
+		{Type: types.SegmentEmoji, Text: "😀", EmojiID: "😀", Unicode: "😀", Shortcut: ":grinning:", Image: "big"},
+		{Type: types.SegmentCustomEmoji, Text: ":yt:", EmojiID: "UC/abc", Shortcut: ":yt:", Image: "custom"},
+		{Type: types.SegmentLink, Text: "docs", URL: "https://go.dev"},
+		{Type: types.SegmentHashtag, Text: "#live", URL: "https://www.youtube.com/hashtag/live"},
+	}
+	if got := parseSegments(runs); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSegments() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := parseSegments(nil); got != nil {
+		t.Errorf("parseSegments(nil) = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,223 @@
+package fetchers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/xorvus/scrap-chat/types"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMetadataTimeout = 10 * time.Second
+	endCheckInterval       = 30 * time.Second
+	upcomingPollInterval   = 30 * time.Second
+)
+
+// FetchLiveMetadata polls updated_metadata and emits a snapshot whenever the
+// viewer count, likes, title, description or live status changes. Failed
+// polls are retried following the reconnect policy. The channel is closed
+// once the stream is no longer live.
+func (y *Youtube) FetchLiveMetadata(path string) (*types.MetadataStream, error) {
+	ctx, cancel := context.WithCancel(y.sessionContext())
+
+	url, err := y.liveURL(ctx, path)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	page, err := y.loadWatchPage(ctx, url, "")
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	if page.videoID == "" {
+		cancel()
+		return nil, types.ErrVideoNotFound
+	}
+
+	metadata := make(chan *types.LiveMetadata)
+	capture, end := types.NewCapture(metadata, cancel, nil)
+
+	go func() {
+		defer cancel()
+		defer close(metadata)
+
+		state := types.LiveMetadata{VideoID: page.videoID, IsLive: true}
+		payload := types.YTPayloadUpdatedMetadata{
+			Context: page.config.INNERTUBE_CONTEXT,
+			VideoID: page.videoID,
+		}
+
+		b := newBackoff(y.reconnect, y.logger)
+		for {
+			var resp *types.YTUpdatedMetadataResponse
+			err := b.retry(ctx, func() error {
+				var err error
+				resp, err = y.updatedMetadata(ctx, &payload)
+				return err
+			})
+			if err != nil {
+				if ctx.Err() == nil {
+					end(err)
+				}
+				return
+			}
+
+			next := applyMetadata(state, resp)
+			if len(next.Changed) > 0 {
+				select {
+				case metadata <- &next:
+				case <-ctx.Done():
+					return
+				}
+			}
+			state = next
+
+			if !state.IsLive {
+				return
+			}
+
+			timeout := defaultMetadataTimeout
+			switch cont := resp.Continuation; {
+			case cont.TimedContinuationData != nil:
+				payload.Continuation = cont.TimedContinuationData.Continuation
+				if cont.TimedContinuationData.TimeoutMs > 0 {
+					timeout = time.Duration(cont.TimedContinuationData.TimeoutMs) * time.Millisecond
+				}
+			case cont.InvalidationContinuationData != nil:
+				payload.Continuation = cont.InvalidationContinuationData.Continuation
+			default:
+				return
+			}
+			payload.VideoID = ""
+
+			if !sleepCtx(ctx, timeout) {
+				return
+			}
+		}
+	}()
+
+	return capture, nil
+}
+
+// watchEnd polls updated_metadata while a chat capture runs and ends it once
+// the video is no longer live. A capture that starts before the stream is
+// live only ends after it went live.
+func (y *Youtube) watchEnd(ctx context.Context, end context.CancelCauseFunc, innertube types.YTInnerTubeContext, videoID string, live bool) {
+	state := types.LiveMetadata{VideoID: videoID, IsLive: live}
+	payload := types.YTPayloadUpdatedMetadata{Context: innertube, VideoID: videoID}
+
+	for sleepCtx(ctx, endCheckInterval) {
+		// Failures are left to the chat stream, it reports its own errors.
+		resp, err := y.updatedMetadata(ctx, &payload)
+		if err != nil {
+			continue
+		}
+		state = applyMetadata(state, resp)
+		live = live || state.IsLive
+		if live && !state.IsLive {
+			end(&types.StreamEndedError{VideoID: videoID, Reason: types.EndNotLive})
+			return
+		}
+	}
+}
+
+func (y *Youtube) updatedMetadata(ctx context.Context, payload *types.YTPayloadUpdatedMetadata) (*types.YTUpdatedMetadataResponse, error) {
+	url := y.baseURL + "/youtubei/v1/updated_metadata?prettyPrint=false"
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fetchError("updatedMetadata", types.ErrorProtocol, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fetchError("updatedMetadata", types.ErrorProtocol, err)
+	}
+
+	res, err := y.httpClient.Do(req)
+	if err != nil {
+		return nil, fetchError("updatedMetadata", types.ErrorNetwork, err)
+	}
+	defer res.Body.Close()
+
+	if err := checkStatus("updatedMetadata", res); err != nil {
+		return nil, err
+	}
+
+	var resp types.YTUpdatedMetadataResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, y.parseError("updatedMetadata", err)
+	}
+
+	return &resp, nil
+}
+
+// applyMetadata returns the state after resp, Changed is reset to the fields
+// resp modified.
+func applyMetadata(state types.LiveMetadata, resp *types.YTUpdatedMetadataResponse) types.LiveMetadata {
+	next := state
+	next.Changed = nil
+	next.Timestamp = time.Now().Unix()
+
+	set := func(field string, changed bool) {
+		if changed && !slices.Contains(next.Changed, field) {
+			next.Changed = append(next.Changed, field)
+		}
+	}
+
+	for _, action := range resp.Actions {
+		switch {
+		case action.UpdateViewershipAction != nil:
+			renderer := action.UpdateViewershipAction.ViewCount.VideoViewCountRenderer
+			next.ViewCountText = textOf(renderer.ViewCount)
+			viewers, err := strconv.ParseInt(renderer.OriginalViewCount, 10, 64)
+			if err != nil {
+				viewers = int64(parseCount(next.ViewCountText))
+			}
+			next.ConcurrentViewers = viewers
+			next.IsLive = renderer.IsLive
+			set("ConcurrentViewers", viewers != state.ConcurrentViewers)
+			set("IsLive", next.IsLive != state.IsLive)
+
+		case action.UpdateToggleButtonTextAction != nil:
+			button := action.UpdateToggleButtonTextAction
+			if button.ButtonID != "TOGGLE_BUTTON_ID_TYPE_LIKE" {
+				continue
+			}
+			next.LikeCountText = textOf(button.DefaultText)
+			next.LikeCount = int64(parseCount(next.LikeCountText))
+			set("LikeCount", next.LikeCount != state.LikeCount)
+
+		case action.UpdateDateTextAction != nil:
+			next.DateText = textOf(action.UpdateDateTextAction.DateText)
+			set("DateText", next.DateText != state.DateText)
+
+		case action.UpdateTitleAction != nil:
+			next.Title = textOf(action.UpdateTitleAction.Title)
+			set("Title", next.Title != state.Title)
+
+		case action.UpdateDescriptionAction != nil:
+			next.Description = textOf(action.UpdateDescriptionAction.Description)
+			set("Description", next.Description != state.Description)
+		}
+	}
+
+	// The like entity carries the exact count, the button text is abbreviated.
+	for _, mutation := range resp.FrameworkUpdates.EntityBatchUpdate.Mutations {
+		if entity := mutation.Payload.LikeCountEntity; entity != nil {
+			if likes, err := strconv.ParseInt(entity.LikeCountIfIndifferentNumber, 10, 64); err == nil {
+				next.LikeCount = likes
+				set("LikeCount", likes != state.LikeCount)
+			}
+		}
+	}
+
+	return next
+}
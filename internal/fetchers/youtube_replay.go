@@ -0,0 +1,143 @@
+package fetchers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/xorvus/scrap-chat/types"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// FetchChatReplay downloads the archived chat of an ended stream or premiere.
+// Messages are delivered in video order with their offset into the video.
+// Failed batches are retried following the reconnect policy. A channel
+// handle is rejected, it does not name a single video.
+func (y *Youtube) FetchChatReplay(path string) (*types.LiveChat, error) {
+	s := y.newSession()
+	capture, err := s.fetchChatReplay(path)
+	if err != nil {
+		s.cancel()
+		return nil, err
+	}
+	return capture, nil
+}
+
+func (s *liveSession) fetchChatReplay(path string) (*types.LiveChat, error) {
+	if strings.HasPrefix(path, "@") || strings.Contains(path, "/@") {
+		return nil, fmt.Errorf("%w: chat replay needs a video, got channel %s", types.ErrVideoNotFound, path)
+	}
+
+	url := path
+	if !strings.HasPrefix(url, "http") {
+		url = s.baseURL + "/watch?v=" + path
+	}
+
+	ctx := s.ctx
+
+	if _, err := s.getConfig(ctx, url); err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	if s.continuation == "" {
+		return nil, types.ErrReplayNotAvailable
+	}
+
+	msg := make(chan *types.LiveChatMessage)
+	capture, end := types.NewCapture(msg, s.cancel, nil)
+	s.end = end
+
+	go func() {
+		defer s.cancel()
+		defer close(msg)
+
+		b := newBackoff(s.reconnect, s.logger)
+		continuation := s.continuation
+		for continuation != "" {
+			var (
+				batch []*types.LiveChatMessage
+				next  string
+			)
+			err := b.retry(ctx, func() error {
+				var err error
+				batch, next, err = s.fetchReplayBatch(ctx, continuation)
+				return err
+			})
+			if err != nil {
+				s.fail(ctx, err)
+				return
+			}
+			continuation = next
+
+			for _, m := range batch {
+				select {
+				case msg <- m:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return capture, nil
+}
+
+func (s *liveSession) fetchReplayBatch(ctx context.Context, continuation string) ([]*types.LiveChatMessage, string, error) {
+	url := s.baseURL + "/youtubei/v1/live_chat/get_live_chat_replay?prettyPrint=false"
+
+	payload := types.YTPayloadChatReplay{
+		Context:      s.config.INNERTUBE_CONTEXT,
+		Continuation: continuation,
+	}
+	payload.CurrentPlayerState.PlayerOffsetMs = "0"
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", fetchError("fetchReplayBatch", types.ErrorProtocol, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", fetchError("fetchReplayBatch", types.ErrorProtocol, err)
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fetchError("fetchReplayBatch", types.ErrorNetwork, err)
+	}
+	defer res.Body.Close()
+
+	if err := checkStatus("fetchReplayBatch", res); err != nil {
+		return nil, "", err
+	}
+
+	var replayResp types.YTChatReplayResponse
+	if err := json.NewDecoder(res.Body).Decode(&replayResp); err != nil {
+		return nil, "", s.parseError("fetchReplayBatch", err)
+	}
+
+	liveChat := replayResp.ContinuationContents.LiveChatContinuation
+
+	next := ""
+	for _, cont := range liveChat.Continuations {
+		if cont.LiveChatReplayContinuationData != nil {
+			next = cont.LiveChatReplayContinuationData.Continuation
+			break
+		}
+	}
+
+	messages := make([]*types.LiveChatMessage, 0, len(liveChat.Actions))
+	for _, action := range liveChat.Actions {
+		replay := action.ReplayChatItemAction
+		offset, _ := strconv.ParseInt(replay.VideoOffsetTimeMsec, 10, 64)
+		for _, param := range s.parseActions(replay.Actions) {
+			m := toLiveChatMessage(param)
+			m.VideoOffsetMs = offset
+			messages = append(messages, m)
+		}
+	}
+
+	return messages, next, nil
+}
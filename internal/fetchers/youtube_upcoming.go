@@ -0,0 +1,50 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"github.com/xorvus/scrap-chat/types"
+	"time"
+)
+
+// startLead is how long before the scheduled start the watch page is checked
+// again, streams often open their chat early.
+const startLead = 2 * time.Minute
+
+// waitForStart blocks until the chat of an upcoming stream or premiere can
+// be captured. A waiting-room chat is opened right away, otherwise the watch
+// page is checked again from shortly before the scheduled start time on.
+func (s *liveSession) waitForStart(ctx context.Context, b *backoff, page *watchPage) (*watchPage, error) {
+	for {
+		switch {
+		case page.continuation != "":
+			err := s.startChat(ctx, b)
+			if err == nil || !page.upcoming || !errors.Is(err, types.ErrStreamNotLive) {
+				return page, err
+			}
+		case !page.upcoming:
+			if err := page.endErr(); err != nil {
+				return nil, err
+			}
+			return nil, types.ErrStreamNotLive
+		}
+
+		delay := upcomingPollInterval
+		if untilStart := time.Until(page.scheduledStart.Add(-startLead)); untilStart > delay {
+			delay = untilStart
+		}
+		s.logger.Info("waiting for stream to start", "scheduled_start", page.scheduledStart, "next_check", delay.Round(time.Second))
+		if !sleepCtx(ctx, delay) {
+			return nil, ctx.Err()
+		}
+
+		err := b.retry(ctx, func() error {
+			var err error
+			page, err = s.getConfig(ctx, s.watchURL)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
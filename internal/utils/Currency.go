@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var currencySymbols = map[string]string{
+	"$":   "USD",
+	"US$": "USD",
+	"CA$": "CAD",
+	"A$":  "AUD",
+	"NZ$": "NZD",
+	"HK$": "HKD",
+	"NT$": "TWD",
+	"MX$": "MXN",
+	"R$":  "BRL",
+	"S$":  "SGD",
+	"€":   "EUR",
+	"£":   "GBP",
+	"¥":   "JPY",
+	"CN¥": "CNY",
+	"₩":   "KRW",
+	"₹":   "INR",
+	"₱":   "PHP",
+	"₫":   "VND",
+	"₪":   "ILS",
+	"₺":   "TRY",
+	"฿":   "THB",
+	"₽":   "RUB",
+	"Rp":  "IDR",
+	"RM":  "MYR",
+	"zł":  "PLN",
+	"R":   "ZAR",
+}
+
+// ParseCurrency splits a purchase amount such as "$5.00", "€5,00" or
+// "IDR 10,000.00" into an ISO 4217 code and the value in micros.
+func ParseCurrency(text string) (string, int64) {
+	var number, symbol strings.Builder
+	for _, r := range text {
+		switch {
+		case unicode.IsDigit(r) || r == '.' || r == ',':
+			number.WriteRune(r)
+		case unicode.IsSpace(r):
+		default:
+			symbol.WriteRune(r)
+		}
+	}
+
+	code := symbol.String()
+	if c, ok := currencySymbols[code]; ok {
+		code = c
+	} else if len(code) != 3 || strings.ToUpper(code) != code {
+		code = ""
+	}
+
+	return code, parseMicros(number.String())
+}
+
+// parseMicros guesses the decimal separator: when both '.' and ',' appear the
+// last one wins, a single separator followed by exactly three digits is a
+// thousands separator.
+func parseMicros(number string) int64 {
+	number = strings.Trim(number, ".,")
+	if number == "" {
+		return 0
+	}
+
+	decimal := -1
+	lastDot := strings.LastIndex(number, ".")
+	lastComma := strings.LastIndex(number, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimal = max(lastDot, lastComma)
+	case lastDot >= 0 || lastComma >= 0:
+		sep := max(lastDot, lastComma)
+		if strings.Count(number, number[sep:sep+1]) == 1 && len(number)-sep-1 != 3 {
+			decimal = sep
+		}
+	}
+
+	whole, fraction := number, ""
+	if decimal >= 0 {
+		whole, fraction = number[:decimal], number[decimal+1:]
+	}
+	whole = strings.NewReplacer(".", "", ",", "").Replace(whole)
+	if len(fraction) > 6 {
+		fraction = fraction[:6]
+	}
+	fraction += strings.Repeat("0", 6-len(fraction))
+
+	micros, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return micros
+}
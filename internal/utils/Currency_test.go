@@ -0,0 +1,50 @@
+package utils
+
+import "testing"
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		text   string
+		code   string
+		micros int64
+	}{
+		{"$5.00", "USD", 5_000_000},
+		{"€5,00", "EUR", 5_000_000},
+		{"IDR 10,000.00", "IDR", 10_000_000_000},
+		{"¥1,000", "JPY", 1_000_000_000},
+		{"CA$2.50", "CAD", 2_500_000},
+		{"R$ 1.234,56", "BRL", 1_234_560_000},
+		{"₹199", "INR", 199_000_000},
+		{"??5", "", 5_000_000},
+	}
+	for _, tt := range tests {
+		code, micros := ParseCurrency(tt.text)
+		if code != tt.code || micros != tt.micros {
+			t.Errorf("ParseCurrency(%q) = %q, %d, want %q, %d", tt.text, code, micros, tt.code, tt.micros)
+		}
+	}
+}
+
+func TestParseMicros(t *testing.T) {
+	tests := []struct {
+		number string
+		want   int64
+	}{
+		{"", 0},
+		{"5", 5_000_000},
+		{"5.5", 5_500_000},
+		{"5,50", 5_500_000},
+		{"1,000", 1_000_000_000},
+		{"1.000", 1_000_000_000},
+		{"1.000.000", 1_000_000_000_000},
+		{"1,234.56", 1_234_560_000},
+		{"1.234,56", 1_234_560_000},
+		{"0.1234567", 123_456},
+		{"5.", 5_000_000},
+	}
+	for _, tt := range tests {
+		if got := parseMicros(tt.number); got != tt.want {
+			t.Errorf("parseMicros(%q) = %d, want %d", tt.number, got, tt.want)
+		}
+	}
+}
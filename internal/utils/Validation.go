@@ -1,11 +1,12 @@
 package utils
 
 import (
-	"log"
+	"log/slog"
 	"reflect"
 )
 
-func CheckEmptyFields(info interface{}) {
+// CheckEmptyFields logs a warning for every empty string field of info.
+func CheckEmptyFields(logger *slog.Logger, info interface{}) {
 	v := reflect.ValueOf(info)
 	t := reflect.TypeOf(info)
 
@@ -13,7 +14,7 @@ func CheckEmptyFields(info interface{}) {
 		fieldValue := v.Field(i)
 		fieldName := t.Field(i).Name
 		if fieldValue.Kind() == reflect.String && fieldValue.String() == "" {
-			log.Printf("%s tidak ditemukan.\n", fieldName)
+			logger.Warn("field not found", "field", fieldName, "type", t.Name())
 		}
 	}
 }
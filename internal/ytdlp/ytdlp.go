@@ -1,21 +1,29 @@
 package ytdlp
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/xorvus/scrap-chat/internal/utils"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
+	"time"
 )
 
 const version = "2025.04.30"
 
 type YtDlp struct {
+	logger *slog.Logger
 }
 
-func NewYtDlp() *YtDlp {
-	return &YtDlp{}
+// NewYtDlp logs to logger, a nil logger logs nothing.
+func NewYtDlp(logger *slog.Logger) *YtDlp {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	return &YtDlp{logger: logger}
 }
 
 func (d *YtDlp) Check() error {
@@ -30,21 +38,25 @@ func (d *YtDlp) Check() error {
 		case "linux", "darwin":
 			url = fmt.Sprintf("https://github.com/yt-dlp/yt-dlp/releases/download/%s/yt-dlp", version)
 		default:
-			return fmt.Errorf("Running on unknown OS: %s\n", os)
+			return fmt.Errorf("running on unknown OS: %s", os)
 		}
 
 		if url != "" {
-			utils.DownloadFile(url, path)
+			start := time.Now()
+			if err := utils.DownloadFile(url, path); err != nil {
+				return fmt.Errorf("download yt-dlp: %w", err)
+			}
+			d.logger.Info("downloaded yt-dlp", "version", version, "endpoint", url, "latency", time.Since(start))
 		}
 	}
 
 	return nil
 }
 
-func (d *YtDlp) DownloadComments(url string) {
+func (d *YtDlp) DownloadComments(url string) error {
 	err := d.Check()
 	if err != nil {
-		log.Fatalln(err)
+		return err
 	}
 
 	outputFile := "comments.json"
@@ -52,8 +64,7 @@ func (d *YtDlp) DownloadComments(url string) {
 	// Create or open the output file
 	file, err := os.Create(outputFile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating file: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error creating file: %w", err)
 	}
 	defer file.Close()
 
@@ -63,14 +74,16 @@ func (d *YtDlp) DownloadComments(url string) {
 	// Redirect command output to the file
 	cmd.Stdout = file
 	// Capture stderr for error handling
-	cmd.Stderr = os.Stderr
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 
 	// Run the command
+	start := time.Now()
 	err = cmd.Run()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error running yt-dlp: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error running yt-dlp: %w: %s", err, strings.TrimSpace(stderr.String()))
 	}
 
-	fmt.Printf("Comments saved to %s\n", outputFile)
+	d.logger.Info("comments saved", "stream", url, "file", outputFile, "latency", time.Since(start))
+	return nil
 }
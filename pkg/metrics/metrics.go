@@ -0,0 +1,171 @@
+// Package metrics is a small Prometheus-compatible registry of counters,
+// gauges and histograms, exposed in the text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Default is the registry fetchers use when none is configured.
+var Default = NewRegistry()
+
+// DefaultBuckets are latency buckets in seconds.
+var DefaultBuckets = []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// Registry holds metric families by name. Asking twice for the same name
+// returns the same family, so several fetchers can share a registry.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindGauge     kind = "gauge"
+	kindHistogram kind = "histogram"
+)
+
+// family is a metric name with its series, one per label values.
+type family struct {
+	name    string
+	help    string
+	kind    kind
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	value  atomicFloat
+	// Histogram only, counts[i] is the number of observations <= buckets[i].
+	counts []atomic.Uint64
+	count  atomic.Uint64
+}
+
+func (r *Registry) family(name, help string, k kind, buckets []float64, labels []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		if f.kind != k || len(f.labels) != len(labels) {
+			panic(fmt.Sprintf("metrics: %s registered as a different metric", name))
+		}
+		return f
+	}
+	f := &family{
+		name:    name,
+		help:    help,
+		kind:    k,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	r.families[name] = f
+	return f
+}
+
+func (f *family) with(values []string) *series {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		if f.kind == kindHistogram {
+			s.counts = make([]atomic.Uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Counter only goes up.
+type Counter struct{ s *series }
+
+func (c Counter) Inc()          { c.s.value.add(1) }
+func (c Counter) Add(v float64) { c.s.value.add(math.Max(v, 0)) }
+
+// CounterVec is a counter split by labels.
+type CounterVec struct{ f *family }
+
+// With returns the counter of the label values, in the order the labels
+// were registered.
+func (v CounterVec) With(values ...string) Counter { return Counter{v.f.with(values)} }
+
+// Gauge goes up and down.
+type Gauge struct{ s *series }
+
+func (g Gauge) Set(v float64) { g.s.value.store(v) }
+func (g Gauge) Add(v float64) { g.s.value.add(v) }
+func (g Gauge) Inc()          { g.s.value.add(1) }
+func (g Gauge) Dec()          { g.s.value.add(-1) }
+
+// Histogram counts observations in cumulative buckets.
+type Histogram struct {
+	s       *series
+	buckets []float64
+}
+
+func (h Histogram) Observe(v float64) {
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.s.counts[i].Add(1)
+		}
+	}
+	h.s.count.Add(1)
+	h.s.value.add(v)
+}
+
+func (r *Registry) Counter(name, help string) Counter {
+	return Counter{r.family(name, help, kindCounter, nil, nil).with(nil)}
+}
+
+func (r *Registry) CounterVec(name, help string, labels ...string) CounterVec {
+	return CounterVec{r.family(name, help, kindCounter, nil, labels)}
+}
+
+func (r *Registry) Gauge(name, help string) Gauge {
+	return Gauge{r.family(name, help, kindGauge, nil, nil).with(nil)}
+}
+
+// Histogram registers a histogram, nil buckets take DefaultBuckets.
+func (r *Registry) Histogram(name, help string, buckets []float64) Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	f := r.family(name, help, kindHistogram, buckets, nil)
+	return Histogram{s: f.with(nil), buckets: f.buckets}
+}
+
+type atomicFloat struct{ bits atomic.Uint64 }
+
+func (f *atomicFloat) load() float64   { return math.Float64frombits(f.bits.Load()) }
+func (f *atomicFloat) store(v float64) { f.bits.Store(math.Float64bits(v)) }
+func (f *atomicFloat) add(delta float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistryWriteTo(t *testing.T) {
+	r := NewRegistry()
+	responses := r.CounterVec("requests_total", "Requests by status.", "status")
+	responses.With("500").Inc()
+	responses.With("200").Add(2)
+	responses.With("200").Add(-1)
+	r.Gauge("captures", "Running captures.\nOne per stream.").Set(3)
+	latency := r.Histogram("latency_seconds", "Latency.", []float64{1, 0.5})
+	latency.Observe(0.25)
+	latency.Observe(2)
+	r.CounterVec("label_escape_total", "Escaped.", "v").With(`a"b\c`).Inc()
+
+	var b strings.Builder
+	n, err := r.WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP captures Running captures.\nOne per stream.
+# TYPE captures gauge
+captures 3
+# HELP label_escape_total Escaped.
+# TYPE label_escape_total counter
+label_escape_total{v="a\"b\\c"} 1
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.5"} 1
+latency_seconds_bucket{le="1"} 1
+latency_seconds_bucket{le="+Inf"} 2
+latency_seconds_sum 2.25
+latency_seconds_count 2
+# HELP requests_total Requests by status.
+# TYPE requests_total counter
+requests_total{status="200"} 2
+requests_total{status="500"} 1
+`
+	if b.String() != want {
+		t.Errorf("WriteTo wrote:\n%s\nwant:\n%s", b.String(), want)
+	}
+	if n != int64(len(want)) {
+		t.Errorf("WriteTo returned %d, wrote %d bytes", n, len(want))
+	}
+}
+
+func TestRegistrySharesFamilies(t *testing.T) {
+	r := NewRegistry()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.CounterVec("hits_total", "Hits.", "route").With("chat").Inc()
+			}
+		}()
+	}
+	wg.Wait()
+
+	var b strings.Builder
+	r.WriteTo(&b)
+	if !strings.Contains(b.String(), `hits_total{route="chat"} 800`+"\n") {
+		t.Errorf("WriteTo wrote:\n%s", b.String())
+	}
+}
+
+func TestRegistryKindMismatch(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("x", "X.")
+	defer func() {
+		if recover() == nil {
+			t.Error("registering x as a gauge did not panic")
+		}
+	}()
+	r.Gauge("x", "X.")
+}
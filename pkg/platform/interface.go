@@ -7,7 +7,11 @@ import (
 
 type ChatFetcher interface {
 	AddCookies(path string) error
-	FetchLiveChat(streamID string) (<-chan *types.LiveChatMessage, error)
+	FetchLiveChat(streamID string) (*types.LiveChat, error)
+	FetchChatReplay(videoID string) (*types.LiveChat, error)
+	FetchLiveMetadata(streamID string) (*types.MetadataStream, error)
 	FetchVideoComments(videoID string, date *time.Time) (<-chan *types.ChatMessage, error)
 	FetchChannelInfo(path string) (*types.ChannelInfo, error)
+	Stop()
+	Stats() types.Stats
 }
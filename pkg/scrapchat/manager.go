@@ -0,0 +1,199 @@
+package scrapchat
+
+import (
+	"context"
+	"github.com/xorvus/scrap-chat/types"
+	"sort"
+	"sync"
+)
+
+// Manager captures the live chat of many streams at once and fans their
+// messages into one channel. The streams share one fetcher, each runs its
+// own session so adding or removing one never disturbs the others.
+type Manager struct {
+	chat     *ScrapChat
+	ctx      context.Context
+	cancel   context.CancelFunc
+	messages chan *types.StreamMessage
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+
+	mu      sync.Mutex
+	streams map[string]*managedStream
+}
+
+type managedStream struct {
+	capture *types.LiveChat
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewManager takes the same options as New, they apply to every stream.
+func NewManager(platform string, opts ...Option) (*Manager, error) {
+	o, err := applyOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(o.ctx)
+
+	chat, err := New(platform, append(append([]Option{}, opts...), WithContext(ctx))...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &Manager{
+		chat:     chat,
+		ctx:      ctx,
+		cancel:   cancel,
+		messages: make(chan *types.StreamMessage),
+		streams:  make(map[string]*managedStream),
+	}, nil
+}
+
+// AddCookies loads a cookie file for every request made afterwards, by all
+// streams.
+func (m *Manager) AddCookies(path string) error {
+	return m.chat.AddCookies(path)
+}
+
+// Messages returns the channel every stream is fanned into. It is closed
+// after Stop.
+func (m *Manager) Messages() <-chan *types.StreamMessage {
+	return m.messages
+}
+
+// Add starts capturing the live chat of streamID. Adding a stream that is
+// still running does nothing, a stream that ended is started again.
+func (m *Manager) Add(streamID string) error {
+	if m.running(streamID) {
+		return nil
+	}
+
+	capture, err := m.chat.FetchLiveChat(streamID)
+	if err != nil {
+		return err
+	}
+	ctx, forwardCancel := context.WithCancel(m.ctx)
+	cancel := func() {
+		capture.Stop()
+		forwardCancel()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.ctx.Err(); err != nil {
+		cancel()
+		return err
+	}
+	if s, ok := m.streams[streamID]; ok {
+		select {
+		case <-s.done:
+		default:
+			// Added concurrently, keep the first capture.
+			cancel()
+			return nil
+		}
+	}
+
+	s := &managedStream{capture: capture, cancel: cancel, done: make(chan struct{})}
+	m.streams[streamID] = s
+	m.wg.Add(1)
+	go m.forward(ctx, streamID, s, capture.Messages)
+
+	return nil
+}
+
+func (m *Manager) forward(ctx context.Context, streamID string, s *managedStream, messages <-chan *types.LiveChatMessage) {
+	defer m.wg.Done()
+	defer close(s.done)
+	defer s.cancel()
+
+	for msg := range messages {
+		select {
+		case m.messages <- &types.StreamMessage{Stream: streamID, LiveChatMessage: msg}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Remove stops capturing streamID, it reports whether the stream was known.
+func (m *Manager) Remove(streamID string) bool {
+	m.mu.Lock()
+	s, ok := m.streams[streamID]
+	delete(m.streams, streamID)
+	m.mu.Unlock()
+
+	if ok {
+		s.cancel()
+	}
+	return ok
+}
+
+func (m *Manager) running(streamID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.streams[streamID]
+	if !ok {
+		return false
+	}
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Streams returns the streams that are still being captured.
+func (m *Manager) Streams() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	streams := make([]string, 0, len(m.streams))
+	for id, s := range m.streams {
+		select {
+		case <-s.done:
+		default:
+			streams = append(streams, id)
+		}
+	}
+	sort.Strings(streams)
+	return streams
+}
+
+// Err returns the error that ended the capture of streamID, see
+// types.Capture.Err. Streams that ended stay known until removed or added again.
+func (m *Manager) Err(streamID string) error {
+	m.mu.Lock()
+	s, ok := m.streams[streamID]
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return s.capture.Err()
+}
+
+// Stats returns the output counters of streamID, see ScrapChat.Stats.
+func (m *Manager) Stats(streamID string) types.Stats {
+	m.mu.Lock()
+	s, ok := m.streams[streamID]
+	m.mu.Unlock()
+	if !ok {
+		return types.Stats{}
+	}
+	return s.capture.Stats()
+}
+
+// Stop ends every capture and closes the Messages channel once all of them
+// have returned.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() {
+		m.mu.Lock()
+		m.cancel()
+		m.mu.Unlock()
+
+		m.wg.Wait()
+		close(m.messages)
+	})
+}
@@ -0,0 +1,256 @@
+package scrapchat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/xorvus/scrap-chat/internal/fetchers"
+	"github.com/xorvus/scrap-chat/pkg/metrics"
+	"github.com/xorvus/scrap-chat/types"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures New and NewManager.
+type Option func(*options) error
+
+type options struct {
+	ctx         context.Context
+	youtube     fetchers.YoutubeOptions
+	cookiesFile string
+}
+
+func applyOptions(opts []Option) (options, error) {
+	o := options{ctx: context.Background()}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return o, err
+		}
+	}
+	return o, nil
+}
+
+// WithContext ties every capture to ctx, cancelling it works like Stop.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) error {
+		if ctx == nil {
+			return errors.New("nil context")
+		}
+		o.ctx = ctx
+		return nil
+	}
+}
+
+// WithVerbose logs debug output, including the long-poll traffic, to stderr
+// when no logger is set with WithLogger.
+func WithVerbose(verbose bool) Option {
+	return func(o *options) error {
+		o.youtube.Verbose = verbose
+		return nil
+	}
+}
+
+// WithLogger sends the library logs to logger, nothing is logged by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) error {
+		if logger == nil {
+			return errors.New("nil logger")
+		}
+		o.youtube.Logger = logger
+		return nil
+	}
+}
+
+// WithHTTPClient sends the requests through client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) error {
+		if client == nil {
+			return errors.New("nil HTTP client")
+		}
+		o.youtube.HTTPClient = client
+		return nil
+	}
+}
+
+// WithTransport sends every request through transport, including the ones
+// of an HTTP client set with WithHTTPClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) error {
+		if transport == nil {
+			return errors.New("nil transport")
+		}
+		o.youtube.Transport = transport
+		return nil
+	}
+}
+
+// WithProxy sends every request through an http, https or socks5 proxy.
+func WithProxy(proxy string) Option {
+	return WithProxyPool(0, proxy)
+}
+
+// WithProxyPool rotates the sessions over proxies. A proxy answered with a
+// 429 is benched for the Retry-After delay, or bench when there is none,
+// 0 means 5 minutes. The pool is shared by every stream of a Manager.
+func WithProxyPool(bench time.Duration, proxies ...string) Option {
+	pool, err := fetchers.NewProxyPool(proxies, bench)
+	return func(o *options) error {
+		if err != nil {
+			return err
+		}
+		o.youtube.Proxies = pool
+		return nil
+	}
+}
+
+// WithRateLimits replaces types.DefaultRateLimits. Fetchers with the same
+// limits share their budgets across the process, the zero value turns rate
+// limiting off.
+func WithRateLimits(limits types.RateLimits) Option {
+	return func(o *options) error {
+		for _, l := range []types.RateLimit{limits.Page, limits.InnerTube, limits.Signaler} {
+			if l.PerSecond < 0 || l.Burst < 0 {
+				return fmt.Errorf("invalid rate limit %+v", l)
+			}
+		}
+		o.youtube.RateLimits = &limits
+		return nil
+	}
+}
+
+// WithMetrics records capture metrics in reg instead of metrics.Default.
+// Serve the registry with its Handler or Serve method.
+func WithMetrics(reg *metrics.Registry) Option {
+	return func(o *options) error {
+		if reg == nil {
+			return errors.New("nil metrics registry")
+		}
+		o.youtube.Metrics = reg
+		return nil
+	}
+}
+
+// WithBaseURLs points the fetcher at other hosts than www.youtube.com and
+// signaler-pa.youtube.com, such as a pkg/ytfake server. Either may be empty
+// to keep its default.
+func WithBaseURLs(youtube, signaler string) Option {
+	return func(o *options) error {
+		for _, raw := range []string{youtube, signaler} {
+			if raw == "" {
+				continue
+			}
+			if u, err := url.Parse(raw); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("invalid base URL %q", raw)
+			}
+		}
+		o.youtube.BaseURL = youtube
+		o.youtube.SignalerURL = signaler
+		return nil
+	}
+}
+
+// WithTimeout limits every request except the long-poll stream.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		if timeout <= 0 {
+			return fmt.Errorf("invalid timeout %s", timeout)
+		}
+		o.youtube.Timeout = timeout
+		return nil
+	}
+}
+
+// WithLanguage sets the InnerTube language and region, e.g. "en" and "US".
+// gl may be empty. Watch pages are still loaded in English, the chat view
+// titles are matched in English.
+func WithLanguage(hl, gl string) Option {
+	return func(o *options) error {
+		if hl == "" {
+			return errors.New("empty language")
+		}
+		o.youtube.Language = hl
+		o.youtube.Region = gl
+		return nil
+	}
+}
+
+// WithUserAgent replaces the browser user agent sent to YouTube.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) error {
+		if userAgent == "" {
+			return errors.New("empty user agent")
+		}
+		o.youtube.UserAgent = userAgent
+		return nil
+	}
+}
+
+// WithCookiesFile loads a Netscape cookies file, the same as AddCookies.
+func WithCookiesFile(path string) Option {
+	return func(o *options) error {
+		o.cookiesFile = path
+		return nil
+	}
+}
+
+// WithChatView selects Top chat or Live chat, the default.
+func WithChatView(view types.ChatView) Option {
+	return func(o *options) error {
+		if view != types.ChatViewTop && view != types.ChatViewLive {
+			return fmt.Errorf("unknown chat view %q", view)
+		}
+		o.youtube.ChatView = view
+		return nil
+	}
+}
+
+// WithPacing sets how live chat batches are spread out, smooth by default.
+func WithPacing(pacing types.Pacing) Option {
+	return func(o *options) error {
+		switch pacing {
+		case types.PacingSmooth, types.PacingImmediate, types.PacingOriginal:
+		default:
+			return fmt.Errorf("unknown pacing %q", pacing)
+		}
+		o.youtube.Pacing = pacing
+		return nil
+	}
+}
+
+// WithBuffer sizes the live chat channel and sets its overflow policy.
+func WithBuffer(buffer types.BufferOptions) Option {
+	return func(o *options) error {
+		if buffer.Size < 0 {
+			return fmt.Errorf("invalid buffer size %d", buffer.Size)
+		}
+		switch buffer.Overflow {
+		case "", types.OverflowBlock, types.OverflowDropOldest, types.OverflowDropNewest, types.OverflowSpill:
+		default:
+			return fmt.Errorf("unknown overflow policy %q", buffer.Overflow)
+		}
+		o.youtube.Buffer = buffer
+		return nil
+	}
+}
+
+// WithReconnectPolicy replaces types.DefaultReconnectPolicy.
+func WithReconnectPolicy(policy types.ReconnectPolicy) Option {
+	return func(o *options) error {
+		if policy.MaxAttempts < 0 {
+			return fmt.Errorf("invalid max attempts %d", policy.MaxAttempts)
+		}
+		o.youtube.Reconnect = &policy
+		return nil
+	}
+}
+
+// WithWaitForStart makes live captures of upcoming streams and premieres
+// wait for the broadcast, capturing the waiting-room chat when there is one.
+func WithWaitForStart(wait bool) Option {
+	return func(o *options) error {
+		o.youtube.Wait = wait
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package scrapchat_test
+
+import (
+	"errors"
+	"github.com/xorvus/scrap-chat/pkg/scrapchat"
+	"github.com/xorvus/scrap-chat/pkg/ytfake"
+	"github.com/xorvus/scrap-chat/types"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// failingChat lets the first get_live_chat request through and answers
+// every later one with 503.
+type failingChat struct {
+	mu    sync.Mutex
+	polls int
+}
+
+func (f *failingChat) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path != "/youtubei/v1/live_chat/get_live_chat" {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	f.mu.Lock()
+	f.polls++
+	first := f.polls == 1
+	f.mu.Unlock()
+	if first {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return &http.Response{
+		Status:     "503 Service Unavailable",
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestReconnectGivesUp(t *testing.T) {
+	srv := ytfake.New(ytfake.Options{
+		Batches:  [][]ytfake.Message{{{ID: "1", Author: "alice", Text: "hello"}}},
+		Interval: 20 * time.Millisecond,
+	})
+	defer srv.Close()
+
+	var (
+		mu          sync.Mutex
+		events      []types.ReconnectEvent
+		reconnected int
+	)
+	transport := &failingChat{}
+	chat, err := scrapchat.New("youtube", append(srv.Options(),
+		scrapchat.WithTransport(transport),
+		scrapchat.WithReconnectPolicy(types.ReconnectPolicy{
+			InitialDelay: 10 * time.Millisecond,
+			MaxDelay:     time.Second,
+			Multiplier:   2,
+			MaxAttempts:  3,
+			OnReconnect: func(e types.ReconnectEvent) {
+				mu.Lock()
+				events = append(events, e)
+				mu.Unlock()
+			},
+			OnReconnected: func(types.ReconnectEvent) {
+				mu.Lock()
+				reconnected++
+				mu.Unlock()
+			},
+		}),
+	)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chat.Stop()
+
+	capture, err := chat.FetchLiveChat(srv.WatchURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg, ok := <-capture.Messages:
+		if ok {
+			t.Errorf("delivered %+v from a failing chat", msg)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("capture kept reconnecting after MaxAttempts")
+	}
+
+	var fe *types.FetchError
+	if err := capture.Err(); !errors.As(err, &fe) || fe.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Err() = %v, want the 503 after MaxAttempts", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	wantDelays := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond}
+	if len(events) != len(wantDelays) {
+		t.Fatalf("reconnect events = %+v, want %d", events, len(wantDelays))
+	}
+	for i, e := range events {
+		if e.Attempt != i+1 || e.Delay != wantDelays[i] {
+			t.Errorf("event %d = attempt %d delay %v, want attempt %d delay %v", i, e.Attempt, e.Delay, i+1, wantDelays[i])
+		}
+	}
+	if reconnected != 0 {
+		t.Errorf("OnReconnected fired %d times without a successful poll", reconnected)
+	}
+	if transport.polls != 1+len(wantDelays)+1 {
+		t.Errorf("sent %d get_live_chat requests, want %d", transport.polls, 1+len(wantDelays)+1)
+	}
+}
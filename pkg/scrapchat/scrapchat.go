@@ -1,56 +1,72 @@
 package scrapchat
 
 import (
-	"context"
+	"errors"
+	"fmt"
 	"github.com/xorvus/scrap-chat/internal/fetchers"
 	plf "github.com/xorvus/scrap-chat/pkg/platform"
 	"github.com/xorvus/scrap-chat/types"
-	"log"
 	"time"
 )
 
+// ErrUnsupportedPlatform is returned by New for a platform it cannot scrape.
+var ErrUnsupportedPlatform = errors.New("platform not supported")
+
 type ScrapChat struct {
 	platform string
 	scrapper plf.ChatFetcher
 }
 
-func New(platform string, opts ...any) *ScrapChat {
+func New(platform string, opts ...Option) (*ScrapChat, error) {
 	var scrapper plf.ChatFetcher
 
-	ctx := context.Background()
-	verbose := false
-
-	for _, opt := range opts {
-		switch v := opt.(type) {
-		case bool:
-			verbose = v
-		case context.Context:
-			ctx = v
-		}
+	o, err := applyOptions(opts)
+	if err != nil {
+		return nil, err
 	}
 
 	switch platform {
 	case "youtube":
-		scrapper = fetchers.NewYoutube(&ctx, verbose)
+		yt, err := fetchers.NewYoutube(o.ctx, o.youtube)
+		if err != nil {
+			return nil, err
+		}
+		scrapper = yt
 	default:
-		log.Fatalf("Platform not support")
-		return nil
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPlatform, platform)
+	}
+
+	if o.cookiesFile != "" {
+		if err := scrapper.AddCookies(o.cookiesFile); err != nil {
+			scrapper.Stop()
+			return nil, err
+		}
 	}
 
 	return &ScrapChat{
 		platform: platform,
 		scrapper: scrapper,
-	}
+	}, nil
 }
 
 func (s *ScrapChat) AddCookies(path string) error {
 	return s.scrapper.AddCookies(path)
 }
 
-func (s *ScrapChat) FetchLiveChat(streamID string) (<-chan *types.LiveChatMessage, error) {
+// FetchLiveChat starts capturing the live chat of streamID. Every capture
+// has its own session, Err of the returned capture tells why it ended.
+func (s *ScrapChat) FetchLiveChat(streamID string) (*types.LiveChat, error) {
 	return s.scrapper.FetchLiveChat(streamID)
 }
 
+func (s *ScrapChat) FetchChatReplay(videoID string) (*types.LiveChat, error) {
+	return s.scrapper.FetchChatReplay(videoID)
+}
+
+func (s *ScrapChat) FetchLiveMetadata(streamID string) (*types.MetadataStream, error) {
+	return s.scrapper.FetchLiveMetadata(streamID)
+}
+
 func (s *ScrapChat) FetchVideoComments(streamID string, date *time.Time) (<-chan *types.ChatMessage, error) {
 	return s.scrapper.FetchVideoComments(streamID, date)
 }
@@ -58,3 +74,15 @@ func (s *ScrapChat) FetchVideoComments(streamID string, date *time.Time) (<-chan
 func (s *ScrapChat) FetchChannelInfo(path string) (*types.ChannelInfo, error) {
 	return s.scrapper.FetchChannelInfo(path)
 }
+
+// Stop cancels every running capture and closes their channels, the same as
+// cancelling the context given to New.
+func (s *ScrapChat) Stop() {
+	s.scrapper.Stop()
+}
+
+// Stats reports how many live chat messages were delivered, dropped or
+// spilled to disk.
+func (s *ScrapChat) Stats() types.Stats {
+	return s.scrapper.Stats()
+}
@@ -0,0 +1,111 @@
+package scrapchat_test
+
+import (
+	"errors"
+	"github.com/xorvus/scrap-chat/pkg/scrapchat"
+	"github.com/xorvus/scrap-chat/pkg/ytfake"
+	"github.com/xorvus/scrap-chat/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var batches = [][]ytfake.Message{
+	{{ID: "1", Author: "alice", Text: "hello"}, {ID: "2", Author: "bob", Text: "hi alice"}},
+	{{ID: "3", Author: "alice", Text: "how are you"}},
+	{{ID: "4", Author: "bob", Text: "fine"}, {ID: "5", Author: "alice", Text: "bye"}},
+}
+
+func collect(t *testing.T, chat *types.LiveChat) []string {
+	t.Helper()
+	var ids []string
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case msg, ok := <-chat.Messages:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, msg.ID)
+		case <-timeout:
+			chat.Stop()
+			t.Fatalf("capture did not end, got %v", ids)
+		}
+	}
+}
+
+func checkEnded(t *testing.T, chat *types.LiveChat) {
+	t.Helper()
+	var ended *types.StreamEndedError
+	if err := chat.Err(); !errors.As(err, &ended) || ended.Reason != types.EndNoContinuation {
+		t.Errorf("Err() = %v, want the stream ended without continuation", err)
+	}
+}
+
+func TestFetchLiveChat(t *testing.T) {
+	for _, cont := range []ytfake.Continuation{ytfake.Timed, ytfake.Invalidation} {
+		t.Run(string(cont), func(t *testing.T) {
+			srv := ytfake.New(ytfake.Options{Continuation: cont, Batches: batches, Interval: 20 * time.Millisecond})
+			defer srv.Close()
+
+			chat, err := scrapchat.New("youtube", append(srv.Options(), scrapchat.WithPacing(types.PacingImmediate))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer chat.Stop()
+
+			capture, err := chat.FetchLiveChat(srv.WatchURL())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := collect(t, capture), []string{"1", "2", "3", "4", "5"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("delivered %v, want %v", got, want)
+			}
+			checkEnded(t, capture)
+			if s := capture.Stats(); s.Delivered != 5 {
+				t.Errorf("capture stats = %+v, want 5 delivered", s)
+			}
+			if cont == ytfake.Invalidation && srv.Hits("GET /punctual/multi-watch/channel") == 0 {
+				t.Error("invalidation capture never long-polled the signaler")
+			}
+		})
+	}
+}
+
+func TestFetchLiveChatConcurrent(t *testing.T) {
+	srv := ytfake.New(ytfake.Options{Batches: batches, Interval: 20 * time.Millisecond})
+	defer srv.Close()
+
+	// Stopping one capture must not end or taint the other.
+	chat, err := scrapchat.New("youtube", append(srv.Options(), scrapchat.WithPacing(types.PacingImmediate))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chat.Stop()
+
+	first, err := chat.FetchLiveChat(srv.WatchURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := chat.FetchLiveChat(srv.WatchURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Stop()
+
+	if got := collect(t, first); len(got) != 5 {
+		t.Errorf("first capture delivered %v, want 5 messages", got)
+	}
+	collect(t, second)
+
+	checkEnded(t, first)
+	if err := second.Err(); err != nil {
+		t.Errorf("stopped capture Err() = %v, want nil", err)
+	}
+	if s := first.Stats(); s.Delivered != 5 {
+		t.Errorf("first capture stats = %+v", s)
+	}
+	if total, s := chat.Stats(), second.Stats(); total.Delivered != 5+s.Delivered {
+		t.Errorf("fetcher delivered %d, want %d", total.Delivered, 5+s.Delivered)
+	}
+}
@@ -0,0 +1,175 @@
+package ytfake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Exchange is one recorded HTTP request and its response. The query string
+// is kept for reference, replay matches on the method and path only since
+// YouTube URLs carry random parameters.
+type Exchange struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// Fixture is a recorded capture, in request order.
+type Fixture struct {
+	Exchanges []Exchange `json:"exchanges"`
+}
+
+// LoadFixture reads a fixture written by Recorder.Save.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Recorder is a RoundTripper that records every exchange going through
+// Base, use it with scrapchat.WithTransport. A response is recorded once
+// its body is closed, long-poll bodies hold what was read until then.
+// Request headers are not recorded, so cookies stay out of fixtures.
+type Recorder struct {
+	// Base defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := r.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		done: func(body []byte) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.fixture.Exchanges = append(r.fixture.Exchanges, Exchange{
+				Method: req.Method,
+				Path:   req.URL.Path,
+				Query:  req.URL.RawQuery,
+				Status: resp.StatusCode,
+				Header: header,
+				Body:   string(body),
+			})
+		},
+	}
+	return resp, nil
+}
+
+// Fixture returns a copy of what was recorded so far.
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Fixture{Exchanges: append([]Exchange(nil), r.fixture.Exchanges...)}
+}
+
+// Save writes the recorded exchanges to path as JSON.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Fixture(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+type recordingBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+	done func([]byte)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.once.Do(func() { b.done(b.buf.Bytes()) })
+	return b.ReadCloser.Close()
+}
+
+// Replayer is a RoundTripper that answers from a fixture without touching
+// the network. Each request takes the next unused exchange with the same
+// method and path, a request with none left gets a 404.
+type Replayer struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	used      []bool
+}
+
+func NewReplayer(f *Fixture) *Replayer {
+	return &Replayer{
+		exchanges: f.Exchanges,
+		used:      make([]bool, len(f.Exchanges)),
+	}
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.exchanges {
+		if r.used[i] || e.Method != req.Method || e.Path != req.URL.Path {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+			StatusCode:    e.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        e.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewBufferString(e.Body)),
+			ContentLength: int64(len(e.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	body := "no recorded exchange for " + req.Method + " " + req.URL.Path
+	return &http.Response{
+		Status:        "404 Not Found",
+		StatusCode:    http.StatusNotFound,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/plain"}},
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package ytfake_test
+
+import (
+	"github.com/xorvus/scrap-chat/pkg/scrapchat"
+	"github.com/xorvus/scrap-chat/pkg/ytfake"
+	"github.com/xorvus/scrap-chat/types"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func capture(t *testing.T, url string, opts ...scrapchat.Option) []string {
+	t.Helper()
+	chat, err := scrapchat.New("youtube", append(opts, scrapchat.WithPacing(types.PacingImmediate))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chat.Stop()
+
+	live, err := chat.FetchLiveChat(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case msg, ok := <-live.Messages:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, msg.ID)
+		case <-timeout:
+			t.Fatalf("capture did not end, got %v", ids)
+		}
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	srv := ytfake.New(ytfake.Options{
+		Continuation: ytfake.Invalidation,
+		Batches: [][]ytfake.Message{
+			{{ID: "1", Author: "alice", Text: "hello"}, {ID: "2", Author: "bob", Text: "hi"}},
+			{{ID: "3", Author: "alice", Text: "bye"}},
+		},
+		Interval: 20 * time.Millisecond,
+	})
+	url := srv.WatchURL()
+	opts := srv.Options()
+
+	rec := &ytfake.Recorder{}
+	recorded := capture(t, url, append(opts, scrapchat.WithTransport(rec))...)
+	srv.Close()
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(recorded, want) {
+		t.Fatalf("recorded %v, want %v", recorded, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := ytfake.LoadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fixture, rec.Fixture()) {
+		t.Error("loaded fixture differs from the recorded one")
+	}
+
+	// The server is gone, every response comes from the fixture.
+	replayed := capture(t, url, append(opts, scrapchat.WithTransport(ytfake.NewReplayer(fixture)))...)
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("replayed %v, want %v", replayed, recorded)
+	}
+}
@@ -0,0 +1,303 @@
+// Package ytfake is an httptest server that plays the YouTube endpoints a
+// live chat capture talks to: the watch page, get_live_chat with timed or
+// invalidation continuations, updated_metadata and the signaler long-poll.
+// Point a fetcher at it with Server.Options to run captures offline.
+package ytfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/xorvus/scrap-chat/pkg/scrapchat"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Continuation picks how the fake chat tells the client to poll.
+type Continuation string
+
+const (
+	// Timed makes the client poll get_live_chat every Interval.
+	Timed Continuation = "timed"
+	// Invalidation makes the client wait for signaler long-poll events.
+	Invalidation Continuation = "invalidation"
+)
+
+// Message is one scripted text chat message.
+type Message struct {
+	ID       string
+	AuthorID string
+	Author   string
+	Text     string
+	// Time defaults to the moment the batch is served.
+	Time time.Time
+}
+
+// Options script the fake stream.
+type Options struct {
+	VideoID   string
+	ChannelID string
+	Title     string
+	// Continuation defaults to Timed.
+	Continuation Continuation
+	// Batches are served in order, one per poll or long-poll event. After the
+	// last one the chat has no continuation left and the capture ends.
+	Batches [][]Message
+	// Interval is the poll timeout or the time between long-poll events,
+	// defaults to 100ms.
+	Interval time.Duration
+}
+
+// Server is a running fake. It is an *httptest.Server, Close stops it.
+type Server struct {
+	*httptest.Server
+	opts Options
+
+	mu   sync.Mutex
+	hits map[string]int
+}
+
+const (
+	sessionToken = "fakeSessionToken"
+	gsessionID   = "fakeGsessionID"
+	sid          = "fakeSID"
+)
+
+// New starts a fake YouTube server scripted by opts.
+func New(opts Options) *Server {
+	if opts.VideoID == "" {
+		opts.VideoID = "fakeVideo01"
+	}
+	if opts.ChannelID == "" {
+		opts.ChannelID = "UCfakeChannel"
+	}
+	if opts.Title == "" {
+		opts.Title = "Fake stream"
+	}
+	if opts.Continuation == "" {
+		opts.Continuation = Timed
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 100 * time.Millisecond
+	}
+
+	s := &Server{opts: opts, hits: make(map[string]int)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /watch", s.watchPage)
+	mux.HandleFunc("GET /channel/{id}/live", s.watchPage)
+	mux.HandleFunc("GET /channel/{id}", s.channelPage)
+	mux.HandleFunc("GET /{handle}", s.channelPage)
+	mux.HandleFunc("POST /youtubei/v1/live_chat/get_live_chat", s.liveChat)
+	mux.HandleFunc("POST /youtubei/v1/updated_metadata", s.updatedMetadata)
+	mux.HandleFunc("POST /punctual/v1/chooseServer", s.chooseServer)
+	mux.HandleFunc("POST /punctual/v1/refreshCreds", s.refreshCreds)
+	mux.HandleFunc("POST /punctual/multi-watch/channel", s.getSID)
+	mux.HandleFunc("GET /punctual/multi-watch/channel", s.longPoll)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits[r.Method+" "+r.URL.Path]++
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	return s
+}
+
+// Options returns the scrapchat options that send every request to s.
+func (s *Server) Options() []scrapchat.Option {
+	return []scrapchat.Option{scrapchat.WithBaseURLs(s.URL, s.URL)}
+}
+
+// WatchURL is the watch page of the scripted stream.
+func (s *Server) WatchURL() string {
+	return s.URL + "/watch?v=" + s.opts.VideoID
+}
+
+// Hits returns how many requests reached "METHOD /path".
+func (s *Server) Hits(route string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[route]
+}
+
+func (s *Server) watchPage(w http.ResponseWriter, r *http.Request) {
+	cfg := map[string]any{
+		"INNERTUBE_API_KEY":           "fakeInnerTubeKey",
+		"INNERTUBE_CLIENT_VERSION":    "2.20250101.00.00",
+		"LIVE_CHAT_BASE_TANGO_CONFIG": map[string]any{"apiKey": "fakeTangoKey"},
+		"INNERTUBE_CONTEXT": map[string]any{
+			"client": map[string]any{"hl": "en", "gl": "US", "clientName": "WEB", "clientVersion": "2.20250101.00.00"},
+		},
+	}
+	player := map[string]any{
+		"videoDetails": map[string]any{"videoId": s.opts.VideoID, "title": s.opts.Title, "isLive": true},
+	}
+	menuItem := func(title, cont string) map[string]any {
+		return map[string]any{
+			"title":        title,
+			"continuation": map[string]any{"reloadContinuationData": map[string]any{"continuation": cont}},
+		}
+	}
+	initial := map[string]any{
+		"currentVideoEndpoint": map[string]any{"watchEndpoint": map[string]any{"videoId": s.opts.VideoID}},
+		"contents": map[string]any{"twoColumnWatchNextResults": map[string]any{"conversationBar": map[string]any{
+			"liveChatRenderer": map[string]any{"header": map[string]any{"liveChatHeaderRenderer": map[string]any{
+				"viewSelector": map[string]any{"sortFilterSubMenuRenderer": map[string]any{"subMenuItems": []any{
+					menuItem("Top chat", continuation(0)),
+					menuItem("Live chat", continuation(0)),
+				}}},
+			}}},
+		}}},
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!DOCTYPE html><html><head><title>%s</title></head><body>\n", html.EscapeString(s.opts.Title))
+	fmt.Fprintf(w, "<script>ytcfg.set(%s);</script>\n", mustJSON(cfg))
+	fmt.Fprintf(w, "<script>var ytInitialPlayerResponse = %s;var meta = null;</script>\n", mustJSON(player))
+	fmt.Fprintf(w, "<script>var ytInitialData = %s;</script>\n", mustJSON(initial))
+	fmt.Fprint(w, "</body></html>\n")
+}
+
+func (s *Server) channelPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<html><head>
+<meta property="og:title" content="%s">
+<meta property="og:image" content="%s/avatar.jpg">
+<meta property="og:description" content="A fake channel">
+<meta property="og:url" content="%s/channel/%s">
+</head><body></body></html>
+`, html.EscapeString(s.opts.Title), s.URL, s.URL, s.opts.ChannelID)
+}
+
+// liveChat serves the batch the continuation points at. Continuation 0 is
+// the one of the watch page and carries no messages, continuation n+1
+// carries batch n.
+func (s *Server) liveChat(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Continuation string `json:"continuation"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return
+	}
+	n, ok := strings.CutPrefix(req.Continuation, "fakeCont")
+	index, err := strconv.Atoi(n)
+	if !ok || err != nil {
+		return
+	}
+
+	var actions []any
+	if index > 0 && index <= len(s.opts.Batches) {
+		for _, m := range s.opts.Batches[index-1] {
+			actions = append(actions, textAction(m))
+		}
+	}
+
+	var continuations []any
+	if index <= len(s.opts.Batches) {
+		data := map[string]any{
+			"continuation": continuation(index + 1),
+			"timeoutMs":    s.opts.Interval.Milliseconds(),
+		}
+		key := "timedContinuationData"
+		if s.opts.Continuation == Invalidation {
+			key = "invalidationContinuationData"
+		}
+		continuations = append(continuations, map[string]any{key: data})
+	}
+
+	writeJSON(w, map[string]any{
+		"continuationContents": map[string]any{"liveChatContinuation": map[string]any{
+			"actions":       actions,
+			"continuations": continuations,
+		}},
+	})
+}
+
+func (s *Server) updatedMetadata(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{})
+}
+
+func (s *Server) chooseServer(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, []any{gsessionID, nil, nil})
+}
+
+func (s *Server) refreshCreds(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, []any{})
+}
+
+func (s *Server) getSID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	body := fmt.Sprintf(`[[0,["c","%s","",8,12,30000]]]`, sid)
+	fmt.Fprintf(w, "%d\n%s\n", len(body), body)
+}
+
+// longPoll opens the session with a first event, then sends one
+// invalidation event per Interval until the client hangs up.
+func (s *Server) longPoll(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("SID") != sid {
+		http.Error(w, "Unknown SID", http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	fmt.Fprintf(w, "[[0,[[null,null,[%q]]]]]\n", sessionToken)
+	flusher.Flush()
+
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	for i := 1; ; i++ {
+		select {
+		case <-r.Context().Done():
+			return
+		case now := <-ticker.C:
+			fmt.Fprintf(w, "[[%d,[{\"t\":\"%d\"}]]]\n", i, now.UnixMicro())
+			flusher.Flush()
+		}
+	}
+}
+
+func textAction(m Message) map[string]any {
+	t := m.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	authorID := m.AuthorID
+	if authorID == "" {
+		authorID = "UCfakeAuthor"
+	}
+	return map[string]any{"addChatItemAction": map[string]any{"item": map[string]any{
+		"liveChatTextMessageRenderer": map[string]any{
+			"id":                      m.ID,
+			"message":                 map[string]any{"runs": []any{map[string]any{"text": m.Text}}},
+			"authorName":              map[string]any{"simpleText": m.Author},
+			"authorExternalChannelId": authorID,
+			"timestampUsec":           strconv.FormatInt(t.UnixMicro(), 10),
+		},
+	}}}
+}
+
+func continuation(n int) string {
+	return "fakeCont" + strconv.Itoa(n)
+}
+
+func mustJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write([]byte(mustJSON(v)))
+}
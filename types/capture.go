@@ -0,0 +1,63 @@
+package types
+
+import "sync"
+
+// Capture is one running capture. Messages is closed when it ends, Err then
+// tells why. Captures of the same fetcher do not share their state.
+type Capture[T any] struct {
+	Messages <-chan T
+
+	stop  func()
+	stats func() Stats
+
+	mu  sync.Mutex
+	err error
+}
+
+// LiveChat is a live chat or chat replay capture.
+type LiveChat = Capture[*LiveChatMessage]
+
+// MetadataStream is a live metadata capture.
+type MetadataStream = Capture[*LiveMetadata]
+
+// NewCapture is used by fetchers, stop cancels the capture and stats may be
+// nil. end records the error that ended the capture, it must be called
+// before messages is closed and only the first non-nil error is kept.
+func NewCapture[T any](messages <-chan T, stop func(), stats func() Stats) (c *Capture[T], end func(error)) {
+	c = &Capture[T]{Messages: messages, stop: stop, stats: stats}
+	return c, c.end
+}
+
+func (c *Capture[T]) end(err error) {
+	if err == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// Err returns the error that ended the capture early, see FetchError and
+// StreamEndedError. It stays nil while the capture runs and when it was
+// stopped.
+func (c *Capture[T]) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Stop cancels the capture, Messages is closed once it has returned.
+func (c *Capture[T]) Stop() {
+	c.stop()
+}
+
+// Stats reports the output counters of this capture, RateLimit is only
+// reported by the fetcher.
+func (c *Capture[T]) Stats() Stats {
+	if c.stats == nil {
+		return Stats{}
+	}
+	return c.stats()
+}
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrStreamNotLive       = errors.New("stream not live")
+	ErrStreamEnded         = errors.New("stream ended")
+	ErrSessionRejected     = errors.New("session rejected")
+	ErrChatViewUnavailable = errors.New("chat view not available")
+	ErrReplayNotAvailable  = errors.New("chat replay not available")
+	ErrVideoNotFound       = errors.New("video not found")
+)
+
+// ErrorKind tells callers whether a failure is worth retrying.
+type ErrorKind string
+
+const (
+	// ErrorNetwork is a transient failure: connection errors, timeouts,
+	// 429 and 5xx responses.
+	ErrorNetwork ErrorKind = "network"
+	// ErrorStreamEnded means the video or its chat is gone (404/410).
+	ErrorStreamEnded ErrorKind = "stream_ended"
+	// ErrorSessionRejected means YouTube refused the session or credentials
+	// (401/403, unknown SID, missing gsessionid).
+	ErrorSessionRejected ErrorKind = "session_rejected"
+	// ErrorProtocol is a response that could not be understood.
+	ErrorProtocol ErrorKind = "protocol"
+)
+
+// FetchError is the error type returned by the fetchers once a capture has
+// started. Use errors.Is with ErrStreamEnded or ErrSessionRejected, or
+// Temporary, to decide what to do.
+type FetchError struct {
+	Kind       ErrorKind
+	Op         string
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *FetchError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s: %s (HTTP %d): %v", e.Op, e.Kind, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %v", e.Op, e.Kind, e.Err)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+func (e *FetchError) Is(target error) bool {
+	switch target {
+	case ErrStreamEnded:
+		return e.Kind == ErrorStreamEnded
+	case ErrSessionRejected:
+		return e.Kind == ErrorSessionRejected
+	}
+	return false
+}
+
+// Temporary reports whether retrying the same request may succeed.
+func (e *FetchError) Temporary() bool {
+	return e.Kind == ErrorNetwork
+}
+
+// EndReason tells which signal ended a live capture.
+type EndReason string
+
+const (
+	// EndNoContinuation means YouTube stopped handing out chat continuations.
+	EndNoContinuation EndReason = "no_continuation"
+	// EndOfflineSlate means the watch page shows the offline slate or an end
+	// time for the broadcast.
+	EndOfflineSlate EndReason = "offline_slate"
+	// EndNotLive means the video metadata switched to not live.
+	EndNotLive EndReason = "not_live"
+)
+
+// StreamEndedError is reported by Err once a broadcast is over and the chat
+// channel was closed. It matches ErrStreamEnded.
+type StreamEndedError struct {
+	VideoID string
+	Reason  EndReason
+}
+
+func (e *StreamEndedError) Error() string {
+	return fmt.Sprintf("stream %s ended: %s", e.VideoID, e.Reason)
+}
+
+func (e *StreamEndedError) Is(target error) bool {
+	return target == ErrStreamEnded
+}
+
+// UpcomingError is returned by FetchLiveChat for a stream or premiere that
+// has not started and has no waiting-room chat. It matches ErrStreamNotLive.
+type UpcomingError struct {
+	VideoID        string
+	ScheduledStart time.Time // zero when the page has no schedule
+}
+
+func (e *UpcomingError) Error() string {
+	if e.ScheduledStart.IsZero() {
+		return fmt.Sprintf("stream %s has not started", e.VideoID)
+	}
+	return fmt.Sprintf("stream %s starts at %s", e.VideoID, e.ScheduledStart.Format(time.RFC3339))
+}
+
+func (e *UpcomingError) Is(target error) bool {
+	return target == ErrStreamNotLive
+}
@@ -9,19 +9,240 @@ type ChannelInfo struct {
 }
 
 type Author struct {
-	ID         string
-	Name       string
-	Thumbnail  string
-	URL        string
-	IsUploader bool
-	IsVerified bool
+	ID          string
+	Name        string
+	Thumbnail   string
+	URL         string
+	IsUploader  bool // channel owner
+	IsVerified  bool
+	IsModerator bool
+	IsMember    bool
+	Member      *MemberBadge `json:",omitempty"`
+}
+
+// MemberBadge is read from the membership badge tooltip, e.g. "Member (6 months)".
+type MemberBadge struct {
+	Label  string
+	Months int
+	Image  string
+}
+
+// ChatView selects which YouTube chat view is captured. Top chat hides
+// messages YouTube considers potential spam.
+type ChatView string
+
+const (
+	ChatViewTop  ChatView = "top"
+	ChatViewLive ChatView = "live"
+)
+
+// Pacing controls how the messages of one live chat batch are spread out.
+type Pacing string
+
+const (
+	// PacingSmooth spreads a batch over the poll interval.
+	PacingSmooth Pacing = "smooth"
+	// PacingImmediate delivers a batch as soon as it is fetched.
+	PacingImmediate Pacing = "immediate"
+	// PacingOriginal keeps the gaps between the message timestamps.
+	PacingOriginal Pacing = "original"
+)
+
+// OverflowPolicy picks what happens when the live chat buffer is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for the consumer, polling stalls meanwhile.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest discards the oldest buffered message.
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowDropNewest discards the incoming message.
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowSpill queues messages in a temporary file until the consumer
+	// catches up.
+	OverflowSpill OverflowPolicy = "spill"
+)
+
+// BufferOptions configures the live chat output channel. SpillDir defaults
+// to the system temporary directory.
+type BufferOptions struct {
+	Size     int
+	Overflow OverflowPolicy
+	SpillDir string
 }
 
+// Stats are the live chat output counters of a capture, or of every capture
+// of a fetcher. Delivered counts the messages put on the channel, including
+// ones later dropped as the oldest. SpillPending is the number of messages
+// waiting in spill files. Late counts messages first seen after a newer one
+// was delivered, they are delivered anyway. RateLimit tells how the requests
+// of each endpoint class were throttled.
+type Stats struct {
+	Delivered     uint64
+	DroppedOldest uint64
+	DroppedNewest uint64
+	Spilled       uint64
+	SpillPending  int64
+	Late          uint64
+	RateLimit     map[EndpointClass]RateLimitStats
+}
+
+type LiveChatMessageType string
+
+const (
+	LiveChatText         LiveChatMessageType = "text"
+	LiveChatSuperChat    LiveChatMessageType = "super_chat"
+	LiveChatSuperSticker LiveChatMessageType = "super_sticker"
+
+	LiveChatMembership               LiveChatMessageType = "membership"
+	LiveChatMembershipMilestone      LiveChatMessageType = "membership_milestone"
+	LiveChatMembershipGift           LiveChatMessageType = "membership_gift"
+	LiveChatMembershipGiftRedemption LiveChatMessageType = "membership_gift_redemption"
+
+	LiveChatDeleted       LiveChatMessageType = "deleted"
+	LiveChatAuthorDeleted LiveChatMessageType = "author_deleted"
+	LiveChatReplaced      LiveChatMessageType = "replaced"
+
+	LiveChatPinned   LiveChatMessageType = "pinned"
+	LiveChatUnpinned LiveChatMessageType = "unpinned"
+
+	LiveChatPollCreated LiveChatMessageType = "poll_created"
+	LiveChatPollUpdated LiveChatMessageType = "poll_updated"
+	LiveChatPollClosed  LiveChatMessageType = "poll_closed"
+	LiveChatQuestion    LiveChatMessageType = "question"
+)
+
 type LiveChatMessage struct {
 	ID        string
+	Type      LiveChatMessageType
 	Message   string
+	Segments  []MessageSegment `json:",omitempty"`
 	Author    Author
 	Timestamp int64
+	// VideoOffsetMs is the position in the video, only set for chat replays.
+	VideoOffsetMs int64              `json:",omitempty"`
+	Paid          *PaidDetails       `json:",omitempty"`
+	Membership    *MembershipDetails `json:",omitempty"`
+	Moderation    *ModerationDetails `json:",omitempty"`
+	Banner        *BannerDetails     `json:",omitempty"`
+	Poll          *PollDetails       `json:",omitempty"`
+}
+
+// StreamMessage is a live chat message tagged with the stream it came from.
+type StreamMessage struct {
+	// Stream is the stream ID or URL the capture was added with.
+	Stream string
+	*LiveChatMessage
+}
+
+type SegmentType string
+
+const (
+	SegmentText        SegmentType = "text"
+	SegmentEmoji       SegmentType = "emoji"
+	SegmentCustomEmoji SegmentType = "custom_emoji"
+	SegmentLink        SegmentType = "link"
+	SegmentMention     SegmentType = "mention"
+	SegmentHashtag     SegmentType = "hashtag"
+)
+
+// MessageSegment is one ordered part of a chat message. Links carry the real
+// target URL instead of YouTube's redirect.
+type MessageSegment struct {
+	Type     SegmentType
+	Text     string
+	URL      string `json:",omitempty"`
+	EmojiID  string `json:",omitempty"`
+	Shortcut string `json:",omitempty"`
+	Unicode  string `json:",omitempty"`
+	Image    string `json:",omitempty"`
+}
+
+// PaidDetails describes a Super Chat or Super Sticker purchase.
+type PaidDetails struct {
+	AmountText   string
+	Currency     string // ISO 4217 code, empty when the symbol is unknown
+	AmountMicros int64
+	Colors       PaidColors
+	Sticker      *Sticker `json:",omitempty"`
+}
+
+// PaidColors are the ARGB colours YouTube uses to render the purchase tier.
+type PaidColors struct {
+	HeaderBackground uint32
+	HeaderText       uint32
+	BodyBackground   uint32
+	BodyText         uint32
+	AuthorName       uint32
+}
+
+// MembershipDetails describes a new member, a membership milestone or a
+// gifted membership.
+type MembershipDetails struct {
+	TierName   string
+	Months     int
+	GiftCount  int
+	HeaderText string
+	Gifter     *Author `json:",omitempty"`
+	Recipient  *Author `json:",omitempty"`
+}
+
+// ModerationDetails references the message (or every message of an author,
+// when banned or timed out) that must be retracted.
+type ModerationDetails struct {
+	TargetID       string
+	TargetAuthorID string
+	StateMessage   string
+	Replacement    *LiveChatMessage `json:",omitempty"`
+}
+
+// BannerDetails describes a banner pinned on top of the chat. Message is the
+// pinned chat item with its original author and timestamp.
+type BannerDetails struct {
+	ActionID   string
+	BannerType string
+	Header     string
+	Message    *LiveChatMessage `json:",omitempty"`
+	PinnedAt   int64
+	UnpinnedAt int64 `json:",omitempty"`
+}
+
+// PollDetails is the state of a live poll when the event was received.
+type PollDetails struct {
+	ID         string
+	PanelID    string
+	PollType   string
+	Question   string
+	Choices    []PollChoice
+	TotalVotes int
+}
+
+type PollChoice struct {
+	Text       string
+	Percentage float64
+	Votes      int
+	Selected   bool
+}
+
+type Sticker struct {
+	Image string
+	Label string
+}
+
+// LiveMetadata is a snapshot of a live stream's audience and details. Changed
+// lists the fields that differ from the previous snapshot.
+type LiveMetadata struct {
+	VideoID           string
+	Title             string
+	Description       string
+	DateText          string
+	ViewCountText     string
+	ConcurrentViewers int64
+	LikeCountText     string
+	LikeCount         int64
+	IsLive            bool
+	Changed           []string
+	Timestamp         int64
 }
 
 type ChatMessage struct {
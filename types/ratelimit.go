@@ -0,0 +1,45 @@
+package types
+
+import "time"
+
+// EndpointClass groups the YouTube endpoints that share a request budget.
+type EndpointClass string
+
+const (
+	// EndpointPage covers watch, live and channel page loads.
+	EndpointPage EndpointClass = "page"
+	// EndpointInnerTube covers the youtubei API, get_live_chat and friends.
+	EndpointInnerTube EndpointClass = "innertube"
+	// EndpointSignaler covers the signaler long-poll, chooseServer, getSID
+	// and refreshCreds.
+	EndpointSignaler EndpointClass = "signaler"
+)
+
+// RateLimit is a token bucket, PerSecond tokens are added up to Burst. A zero
+// PerSecond does not limit the class.
+type RateLimit struct {
+	PerSecond float64
+	Burst     int
+}
+
+// RateLimits are the request budgets of each endpoint class. Fetchers with
+// the same limits share one set of buckets in the process.
+type RateLimits struct {
+	Page      RateLimit
+	InnerTube RateLimit
+	Signaler  RateLimit
+}
+
+var DefaultRateLimits = RateLimits{
+	Page:      RateLimit{PerSecond: 1, Burst: 5},
+	InnerTube: RateLimit{PerSecond: 10, Burst: 20},
+	Signaler:  RateLimit{PerSecond: 5, Burst: 10},
+}
+
+// RateLimitStats tell how many requests of a fetcher were held back by the
+// rate limiter and how long they waited in total.
+type RateLimitStats struct {
+	Requests uint64
+	Waited   uint64
+	WaitTime time.Duration
+}
@@ -0,0 +1,38 @@
+package types
+
+import "time"
+
+// ReconnectPolicy controls how a live capture recovers from network failures
+// and rejected sessions. Zero fields take the DefaultReconnectPolicy values,
+// except Jitter where 0 keeps the delays exact and MaxAttempts where 0
+// retries forever.
+type ReconnectPolicy struct {
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	// Jitter is the fraction of each delay that is randomised, from 0 to 1.
+	Jitter float64
+	// MaxAttempts is the number of consecutive failed attempts before the
+	// capture gives up and Err reports the last error.
+	MaxAttempts int
+	// OnReconnect is called before waiting for the next attempt.
+	OnReconnect func(ReconnectEvent)
+	// OnReconnected is called once the capture works again.
+	OnReconnected func(ReconnectEvent)
+}
+
+// ReconnectEvent describes one reconnect attempt. Err is the failure that
+// triggered it, Delay is how long the fetcher waits before retrying.
+type ReconnectEvent struct {
+	Attempt int
+	Delay   time.Duration
+	Err     error
+}
+
+var DefaultReconnectPolicy = ReconnectPolicy{
+	InitialDelay: time.Second,
+	MaxDelay:     2 * time.Minute,
+	Multiplier:   2,
+	Jitter:       0.5,
+	MaxAttempts:  10,
+}
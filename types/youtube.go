@@ -59,23 +59,150 @@ type YTChatMessagesResponse struct {
 
 type YTActions struct {
 	AddChatItemAction struct {
-		Item struct {
-			LiveChatTextMessageRenderer struct {
-				ID      string `json:"id"`
-				Message struct {
-					Runs []YTRuns `json:"runs"`
-				} `json:"message"`
-				AuthorName struct {
-					SimpleText string `json:"simpleText"`
-				}
-				AuthorPhoto struct {
-					Thumbnails []YTThumbnails `json:"thumbnails"`
-				} `json:"authorPhoto"`
-				AuthorExternalChannelID string `json:"authorExternalChannelId"`
-				TimestampUsec           string `json:"timestampUsec"`
-			} `json:"liveChatTextMessageRenderer"`
-		} `json:"item"`
+		Item YTChatItem `json:"item"`
 	} `json:"addChatItemAction"`
+	MarkChatItemAsDeletedAction *struct {
+		DeletedStateMessage YTText `json:"deletedStateMessage"`
+		TargetItemID        string `json:"targetItemId"`
+	} `json:"markChatItemAsDeletedAction,omitempty"`
+	MarkChatItemsByAuthorAsDeletedAction *struct {
+		DeletedStateMessage YTText `json:"deletedStateMessage"`
+		ExternalChannelID   string `json:"externalChannelId"`
+	} `json:"markChatItemsByAuthorAsDeletedAction,omitempty"`
+	ReplaceChatItemAction *struct {
+		TargetItemID    string     `json:"targetItemId"`
+		ReplacementItem YTChatItem `json:"replacementItem"`
+	} `json:"replaceChatItemAction,omitempty"`
+	AddBannerToLiveChatCommand *struct {
+		BannerRenderer struct {
+			LiveChatBannerRenderer YTBannerRenderer `json:"liveChatBannerRenderer"`
+		} `json:"bannerRenderer"`
+	} `json:"addBannerToLiveChatCommand,omitempty"`
+	RemoveBannerForLiveChatCommand *struct {
+		TargetActionID string `json:"targetActionId"`
+	} `json:"removeBannerForLiveChatCommand,omitempty"`
+	ShowLiveChatActionPanelAction *struct {
+		PanelToShow struct {
+			LiveChatActionPanelRenderer struct {
+				ID       string `json:"id"`
+				Contents struct {
+					PollRenderer *YTPollRenderer `json:"pollRenderer,omitempty"`
+				} `json:"contents"`
+			} `json:"liveChatActionPanelRenderer"`
+		} `json:"panelToShow"`
+	} `json:"showLiveChatActionPanelAction,omitempty"`
+	UpdateLiveChatPollAction *struct {
+		PollToUpdate struct {
+			PollRenderer YTPollRenderer `json:"pollRenderer"`
+		} `json:"pollToUpdate"`
+	} `json:"updateLiveChatPollAction,omitempty"`
+	CloseLiveChatActionPanelAction *struct {
+		TargetPanelID string `json:"targetPanelId"`
+	} `json:"closeLiveChatActionPanelAction,omitempty"`
+}
+
+type YTPollRenderer struct {
+	LiveChatPollID string `json:"liveChatPollId"`
+	Choices        []struct {
+		Text           YTText  `json:"text"`
+		Selected       bool    `json:"selected"`
+		VoteRatio      float64 `json:"voteRatio"`
+		VotePercentage YTText  `json:"votePercentage"`
+	} `json:"choices"`
+	Header struct {
+		PollHeaderRenderer struct {
+			PollQuestion     YTText `json:"pollQuestion"`
+			MetadataText     YTText `json:"metadataText"`
+			LiveChatPollType string `json:"liveChatPollType"`
+		} `json:"pollHeaderRenderer"`
+	} `json:"header"`
+}
+
+type YTBannerRenderer struct {
+	Header struct {
+		LiveChatBannerHeaderRenderer struct {
+			Text YTText `json:"text"`
+		} `json:"liveChatBannerHeaderRenderer"`
+	} `json:"header"`
+	Contents   YTChatItem `json:"contents"`
+	ActionID   string     `json:"actionId"`
+	TargetID   string     `json:"targetId"`
+	BannerType string     `json:"bannerType"`
+}
+
+type YTChatItem struct {
+	LiveChatTextMessageRenderer *YTChatItemRenderer `json:"liveChatTextMessageRenderer,omitempty"`
+	LiveChatPaidMessageRenderer *YTChatItemRenderer `json:"liveChatPaidMessageRenderer,omitempty"`
+	LiveChatPaidStickerRenderer *YTChatItemRenderer `json:"liveChatPaidStickerRenderer,omitempty"`
+
+	LiveChatMembershipItemRenderer                         *YTChatItemRenderer `json:"liveChatMembershipItemRenderer,omitempty"`
+	LiveChatSponsorshipsGiftPurchaseAnnouncementRenderer   *YTChatItemRenderer `json:"liveChatSponsorshipsGiftPurchaseAnnouncementRenderer,omitempty"`
+	LiveChatSponsorshipsGiftRedemptionAnnouncementRenderer *YTChatItemRenderer `json:"liveChatSponsorshipsGiftRedemptionAnnouncementRenderer,omitempty"`
+}
+
+// YTChatItemRenderer holds the union of the fields used by the live chat item
+// renderers, they share most of their layout.
+type YTChatItemRenderer struct {
+	ID      string `json:"id"`
+	Message struct {
+		Runs []YTRuns `json:"runs"`
+	} `json:"message"`
+	AuthorName struct {
+		SimpleText string `json:"simpleText"`
+	} `json:"authorName"`
+	AuthorPhoto struct {
+		Thumbnails []YTThumbnails `json:"thumbnails"`
+	} `json:"authorPhoto"`
+	AuthorExternalChannelID string           `json:"authorExternalChannelId"`
+	AuthorBadges            []YTAuthorBadges `json:"authorBadges"`
+	TimestampUsec           string           `json:"timestampUsec"`
+
+	PurchaseAmountText struct {
+		SimpleText string `json:"simpleText"`
+	} `json:"purchaseAmountText"`
+	HeaderBackgroundColor    uint32 `json:"headerBackgroundColor"`
+	HeaderTextColor          uint32 `json:"headerTextColor"`
+	BodyBackgroundColor      uint32 `json:"bodyBackgroundColor"`
+	BodyTextColor            uint32 `json:"bodyTextColor"`
+	AuthorNameTextColor      uint32 `json:"authorNameTextColor"`
+	MoneyChipBackgroundColor uint32 `json:"moneyChipBackgroundColor"`
+	MoneyChipTextColor       uint32 `json:"moneyChipTextColor"`
+	BackgroundColor          uint32 `json:"backgroundColor"`
+	Sticker                  struct {
+		Thumbnails    []YTThumbnails  `json:"thumbnails"`
+		Accessibility YTAccessibility `json:"accessibility"`
+	} `json:"sticker"`
+
+	HeaderPrimaryText YTText `json:"headerPrimaryText"`
+	HeaderSubtext     YTText `json:"headerSubtext"`
+	PrimaryText       YTText `json:"primaryText"`
+	Header            struct {
+		LiveChatSponsorshipsHeaderRenderer *YTChatItemRenderer `json:"liveChatSponsorshipsHeaderRenderer,omitempty"`
+	} `json:"header"`
+}
+
+// YTText is a formatted string, YouTube sends either simpleText or runs.
+type YTText struct {
+	SimpleText string   `json:"simpleText,omitempty"`
+	Runs       []YTRuns `json:"runs,omitempty"`
+}
+
+type YTAuthorBadges struct {
+	LiveChatAuthorBadgeRenderer struct {
+		Icon struct {
+			IconType string `json:"iconType"`
+		} `json:"icon"`
+		CustomThumbnail struct {
+			Thumbnails []YTThumbnails `json:"thumbnails"`
+		} `json:"customThumbnail"`
+		Tooltip string `json:"tooltip"`
+	} `json:"liveChatAuthorBadgeRenderer"`
+}
+
+type YTAccessibility struct {
+	AccessibilityData struct {
+		Label string `json:"label"`
+	} `json:"accessibilityData"`
 }
 
 type YTThumbnails struct {
@@ -86,15 +213,27 @@ type YTThumbnails struct {
 
 type YTRuns struct {
 	Text  string `json:"text,omitempty"`
+	Bold  bool   `json:"bold,omitempty"`
 	Emoji struct {
-		EmojiId       string `json:"emojiId"`
-		IsCustomEmoji bool   `json:"isCustomEmoji,omitempty"`
+		EmojiId       string   `json:"emojiId"`
+		Shortcuts     []string `json:"shortcuts,omitempty"`
+		IsCustomEmoji bool     `json:"isCustomEmoji,omitempty"`
 		Image         struct {
 			Thumbnails []struct {
 				Url string `json:"url,omitempty"`
 			}
 		}
 	} `json:"emoji,omitempty"`
+	NavigationEndpoint *struct {
+		URLEndpoint struct {
+			URL string `json:"url"`
+		} `json:"urlEndpoint"`
+		CommandMetadata struct {
+			WebCommandMetadata struct {
+				URL string `json:"url"`
+			} `json:"webCommandMetadata"`
+		} `json:"commandMetadata"`
+	} `json:"navigationEndpoint,omitempty"`
 }
 
 type InvalidationContinuationData struct {
@@ -106,20 +245,101 @@ type TimedContinuationData struct {
 	Continuation string `json:"continuation"`
 	TimeoutMs    int    `json:"timeoutMs"`
 }
+type LiveChatReplayContinuationData struct {
+	Continuation             string `json:"continuation"`
+	TimeUntilLastMessageMsec int    `json:"timeUntilLastMessageMsec"`
+}
+
 type YTContinuationChat struct {
-	TimedContinuationData        *TimedContinuationData        `json:"timedContinuationData,omitempty"`
-	InvalidationContinuationData *InvalidationContinuationData `json:"invalidationContinuationData,omitempty"`
+	TimedContinuationData          *TimedContinuationData          `json:"timedContinuationData,omitempty"`
+	InvalidationContinuationData   *InvalidationContinuationData   `json:"invalidationContinuationData,omitempty"`
+	LiveChatReplayContinuationData *LiveChatReplayContinuationData `json:"liveChatReplayContinuationData,omitempty"`
+}
+
+type YTPayloadChatReplay struct {
+	Context            YTInnerTubeContext `json:"context"`
+	Continuation       string             `json:"continuation"`
+	CurrentPlayerState struct {
+		PlayerOffsetMs string `json:"playerOffsetMs"`
+	} `json:"currentPlayerState"`
+}
+
+type YTChatReplayResponse struct {
+	ContinuationContents struct {
+		LiveChatContinuation struct {
+			Actions []struct {
+				ReplayChatItemAction struct {
+					Actions             []YTActions `json:"actions"`
+					VideoOffsetTimeMsec string      `json:"videoOffsetTimeMsec"`
+				} `json:"replayChatItemAction"`
+			} `json:"actions"`
+			Continuations []YTContinuationChat `json:"continuations"`
+		} `json:"liveChatContinuation"`
+	} `json:"continuationContents"`
 }
 
 type YTChatMessage struct {
-	ID        string
-	Message   string
-	Author    YTAuthor
-	Timestamp time.Time
+	ID         string
+	Type       LiveChatMessageType
+	Message    string
+	Segments   []MessageSegment
+	Author     YTAuthor
+	Timestamp  time.Time
+	Paid       *PaidDetails
+	Membership *MembershipDetails
+	Moderation *ModerationDetails
+	Banner     *BannerDetails
+	Poll       *PollDetails
 }
 
 type YTAuthor struct {
 	AuthorID     string
 	AuthorName   string
 	AuthorImages []YTThumbnails
+	AuthorBadges []YTAuthorBadges
+}
+
+type YTPayloadUpdatedMetadata struct {
+	Context      YTInnerTubeContext `json:"context"`
+	VideoID      string             `json:"videoId,omitempty"`
+	Continuation string             `json:"continuation,omitempty"`
+}
+
+type YTUpdatedMetadataResponse struct {
+	Continuation YTContinuationChat `json:"continuation"`
+	Actions      []struct {
+		UpdateViewershipAction *struct {
+			ViewCount struct {
+				VideoViewCountRenderer struct {
+					ViewCount         YTText `json:"viewCount"`
+					IsLive            bool   `json:"isLive"`
+					OriginalViewCount string `json:"originalViewCount"`
+				} `json:"videoViewCountRenderer"`
+			} `json:"viewCount"`
+		} `json:"updateViewershipAction,omitempty"`
+		UpdateToggleButtonTextAction *struct {
+			DefaultText YTText `json:"defaultText"`
+			ButtonID    string `json:"buttonId"`
+		} `json:"updateToggleButtonTextAction,omitempty"`
+		UpdateDateTextAction *struct {
+			DateText YTText `json:"dateText"`
+		} `json:"updateDateTextAction,omitempty"`
+		UpdateTitleAction *struct {
+			Title YTText `json:"title"`
+		} `json:"updateTitleAction,omitempty"`
+		UpdateDescriptionAction *struct {
+			Description YTText `json:"description"`
+		} `json:"updateDescriptionAction,omitempty"`
+	} `json:"actions"`
+	FrameworkUpdates struct {
+		EntityBatchUpdate struct {
+			Mutations []struct {
+				Payload struct {
+					LikeCountEntity *struct {
+						LikeCountIfIndifferentNumber string `json:"likeCountIfIndifferentNumber"`
+					} `json:"likeCountEntity,omitempty"`
+				} `json:"payload"`
+			} `json:"mutations"`
+		} `json:"entityBatchUpdate"`
+	} `json:"frameworkUpdates"`
 }